@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/services/kfserver"
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+// Launcher configuration file key holding the custom game modes
+const gameModesKey = "gamemodes"
+
+// readLauncherConfig merges the launcher configuration file, if any, into config.
+// Its values take precedence over the defaults only, flags and environment variables win.
+// The custom game modes it declares are returned rather than registered, so they can be
+// checked along with the other settings first.
+func readLauncherConfig(flags *pflag.FlagSet, config *viper.Viper) ([]kfserver.GameMode, error) {
+	configFile := viper.GetString("launcher-config")
+	if configFile == "" {
+		return nil, nil
+	}
+
+	fileConfig, err := readConfigFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the launcher configuration file '%s': %w", configFile, err)
+	}
+
+	if err := checkConfigKeys(fileConfig, flags, gameModesKey); err != nil {
+		return nil, fmt.Errorf("invalid launcher configuration file '%s': %w", configFile, err)
+	}
+
+	gameModes, err := readGameModes(fileConfig)
+	if err != nil {
+		return nil, fmt.Errorf("invalid launcher configuration file '%s': %w", configFile, err)
+	}
+
+	values := fileConfig.AllSettings()
+	delete(values, gameModesKey)
+	return gameModes, config.MergeConfigMap(values)
+}
+
+// readGameModes reads the custom game modes declared in the launcher configuration file.
+func readGameModes(config *viper.Viper) ([]kfserver.GameMode, error) {
+	var gameModes []kfserver.GameMode
+	if err := config.UnmarshalKey(gameModesKey, &gameModes); err != nil {
+		return nil, fmt.Errorf("invalid game modes: %w", err)
+	}
+
+	for i, mode := range gameModes {
+		mode, err := kfserver.NormalizeGameMode(mode)
+		if err != nil {
+			return nil, err
+		}
+		gameModes[i] = mode
+	}
+	return gameModes, nil
+}
+
+// registerGameModes registers the custom game modes read from the launcher configuration file.
+func registerGameModes(gameModes []kfserver.GameMode) error {
+	for _, mode := range gameModes {
+		if err := kfserver.RegisterGameMode(mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyProfiles merges the selected profiles into config, in order, on top of the launcher configuration file.
+// Flags and environment variables still take precedence over them.
+func applyProfiles(flags *pflag.FlagSet, config *viper.Viper) error {
+	profiles := strings.FieldsFunc(viper.GetString("profile"), func(r rune) bool { return r == ',' })
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	profileFile := viper.GetString("profile-file")
+	if profileFile == "" {
+		return fmt.Errorf("profiles %v selected but no profile file was given", profiles)
+	}
+
+	profilesConfig, err := readConfigFile(profileFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the profile file '%s': %w", profileFile, err)
+	}
+
+	for _, name := range profiles {
+		name = strings.TrimSpace(name)
+
+		profileConfig := profilesConfig.Sub(name)
+		if profileConfig == nil {
+			return fmt.Errorf("profile '%s' not found in '%s'", name, profileFile)
+		}
+
+		if err := checkConfigKeys(profileConfig, flags); err != nil {
+			return fmt.Errorf("invalid profile '%s' in '%s': %w", name, profileFile, err)
+		}
+
+		if err := config.MergeConfigMap(profileConfig.AllSettings()); err != nil {
+			return fmt.Errorf("failed to apply profile '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+func readConfigFile(filePath string) (*viper.Viper, error) {
+	fileConfig := viper.New()
+	fileConfig.SetConfigFile(filePath)
+	if err := fileConfig.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return fileConfig, nil
+}
+
+// checkConfigKeys rejects unknown keys so typos don't go unnoticed.
+func checkConfigKeys(config *viper.Viper, flags *pflag.FlagSet, extraKeys ...string) error {
+	for _, key := range config.AllKeys() {
+		if flags.Lookup(key) == nil && !slices.Contains(extraKeys, key) {
+			return fmt.Errorf("unknown setting '%s'", key)
+		}
+	}
+	return nil
+}
+
+// readSecretFiles merges into config the value of every sensitive setting read from the file set
+// in its '_FILE' environment variable, if any (e.g. KF_ADMINPASSWORD_FILE).
+func readSecretFiles(flags *pflag.FlagSet, config *viper.Viper) error {
+	// Register the arguments into a scratch instance to find out which ones are sensitive
+	scratch := &settings.KFDSLSettings{}
+	registerArguments(scratch, nil)
+
+	keys := map[string]string{}
+	for _, arg := range scratch.Arguments() {
+		if arg.IsSensitive() {
+			keys[arg.Flag] = envName(arg.Flag)
+		}
+	}
+
+	for key, env := range keys {
+		fileEnv := env + "_FILE"
+		filePath := os.Getenv(fileEnv)
+		if filePath == "" {
+			continue
+		}
+
+		// An explicit flag still takes precedence
+		if flag := flags.Lookup(key); flag != nil && flag.Changed {
+			continue
+		}
+
+		if _, exists := os.LookupEnv(env); exists {
+			return fmt.Errorf("both %s and %s are set, only one of them is allowed", env, fileEnv)
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileEnv, err)
+		}
+		// The environment variable isn't set, so the file value only gives way to a flag, as checked above
+		if err := config.MergeConfigMap(map[string]any{key: strings.TrimSpace(string(data))}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
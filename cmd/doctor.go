@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+func BuildDoctorCommand(doctor func(sett *settings.KFDSLSettings, out io.Writer, minFreeSpaceMB int) error) *cobra.Command {
+	var minFreeSpace int
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Run preflight diagnostics",
+		Long:  "Check that SteamCMD, the server files, the configuration file and the disk are ready to run the server. Exits with a non-zero status if any check fails.",
+		Args:  cobra.NoArgs,
+		// Failures are already reported by the checks
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return doctor(settings.Get(), cmd.OutOrStdout(), minFreeSpace)
+		},
+	}
+	doctorCmd.Flags().IntVar(&minFreeSpace, "min-free-space", 1024, "minimum free disk space (MB)")
+	return doctorCmd
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+func BuildDriftCommand(detect func(sett *settings.KFDSLSettings, out io.Writer) error) *cobra.Command {
+	return &cobra.Command{
+		Use:          "drift",
+		Short:        "Report the configuration values changed outside of the launcher",
+		Long:         "Compare the configuration files to the values the launcher last wrote and list the changed ones. Exits with an error if any value has changed.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return detect(settings.Get(), cmd.OutOrStdout())
+		},
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/services/base"
+	"github.com/K4rian/kfdsl/internal/services/kfserver"
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+func BuildHealthcheckCommand() *cobra.Command {
+	var host string
+	var timeout time.Duration
+
+	healthcheckCmd := &cobra.Command{
+		Use:   "healthcheck",
+		Short: "Check whether the server is up",
+		Long: "Exit with a zero status only if a server started by the launcher is running and answers on its query port " +
+			"(and on its WebAdmin port, if enabled). Intended for the Docker HEALTHCHECK instruction.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			if err := checkServerHealth(ctx, settings.Get(), host); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "healthy")
+			return nil
+		},
+	}
+	healthcheckCmd.Flags().StringVar(&host, "host", "127.0.0.1", "server address to probe")
+	healthcheckCmd.Flags().DurationVar(&timeout, "timeout", 5*time.Second, "maximum duration of the check")
+	return healthcheckCmd
+}
+
+func checkServerHealth(ctx context.Context, sett *settings.KFDSLSettings, host string) error {
+	stateFile := kfserver.StateFilePath(viper.GetString("steamcmd-appinstalldir"))
+
+	// The state file only exists while the server process is running
+	state, err := base.ReadState(stateFile)
+	if err != nil {
+		return fmt.Errorf("server is not running: %w", err)
+	}
+
+	if err := syscall.Kill(state.PID, 0); err != nil && !errors.Is(err, syscall.EPERM) {
+		return fmt.Errorf("server process %d is not running: %w", state.PID, err)
+	}
+
+	log.Logger.Debug("Server process is running",
+		"function", "checkServerHealth", "pid", state.PID, "startedAt", state.StartedAt)
+
+	queryAddress := net.JoinHostPort(host, strconv.Itoa(sett.GamePort.Value()+1))
+	if err := kfserver.QueryServer(ctx, queryAddress); err != nil {
+		return fmt.Errorf("server query failed: %w", err)
+	}
+
+	if sett.EnableWebAdmin.Value() {
+		var dialer net.Dialer
+		webAdminAddress := net.JoinHostPort(host, strconv.Itoa(sett.WebAdminPort.Value()))
+		conn, err := dialer.DialContext(ctx, "tcp", webAdminAddress)
+		if err != nil {
+			return fmt.Errorf("WebAdmin is unreachable: %w", err)
+		}
+		conn.Close()
+	}
+	return nil
+}
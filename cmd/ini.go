@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/config/ini"
+	"github.com/K4rian/kfdsl/internal/utils"
+)
+
+func BuildIniCommand() *cobra.Command {
+	iniCmd := &cobra.Command{
+		Use:   "ini",
+		Short: "Read and edit the server ini files",
+		Long: "Read and edit any ini file of the server System directory.\n" +
+			"Keys are addressed as 'Section.Key', e.g. 'Engine.GameInfo.MaxPlayers'.",
+	}
+
+	var unique bool
+	var index int
+	var encoding string
+
+	getCmd := &cobra.Command{
+		Use:          "get <file> <Section.Key>",
+		Short:        "Print the value(s) of a key",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			iniFile, _, err := loadSystemIniFile(args[0], false, ini.ParseLenient, encoding)
+			if err != nil {
+				return err
+			}
+
+			section, key, err := parseKeyAddress(args[1])
+			if err != nil {
+				return err
+			}
+
+			values := iniFile.GetKeys(section, key)
+			if values == nil {
+				return fmt.Errorf("key not found: [%s].%s", section, key)
+			}
+			for _, value := range values {
+				fmt.Fprintln(cmd.OutOrStdout(), value)
+			}
+			return nil
+		},
+	}
+
+	setCmd := &cobra.Command{
+		Use:          "set <file> <Section.Key> <value>",
+		Short:        "Set the value of a key, replacing all the existing ones",
+		Args:         cobra.ExactArgs(3),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return editSystemIniFile(args[0], true, encoding, func(iniFile *ini.GenericIniFile) error {
+				section, key, err := parseKeyAddress(args[1])
+				if err != nil {
+					return err
+				}
+
+				// A multi-value key is left with the new value only
+				if !iniFile.SetKeys(section, key, []string{args[2]}) {
+					return fmt.Errorf("unable to set [%s].%s to %s", section, key, args[2])
+				}
+				return nil
+			})
+		},
+	}
+
+	addCmd := &cobra.Command{
+		Use:          "add <file> <Section.Key> <value>",
+		Short:        "Append a value to a multi-value key (e.g. ServerActors)",
+		Args:         cobra.ExactArgs(3),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return editSystemIniFile(args[0], true, encoding, func(iniFile *ini.GenericIniFile) error {
+				section, key, err := parseKeyAddress(args[1])
+				if err != nil {
+					return err
+				}
+
+				// Don't add the same value twice
+				if unique {
+					for _, value := range iniFile.GetKeys(section, key) {
+						if value == args[2] {
+							return nil
+						}
+					}
+				}
+
+				if !iniFile.SetKey(section, key, args[2], false) {
+					return fmt.Errorf("unable to add %s to [%s].%s", args[2], section, key)
+				}
+				return nil
+			})
+		},
+	}
+	addCmd.Flags().BoolVar(&unique, "unique", true, "skip the value if the key already holds it")
+
+	delCmd := &cobra.Command{
+		Use:   "del <file> <Section.Key> [value]",
+		Short: "Delete a key",
+		Long: "Delete every occurrence of a key.\n" +
+			"If a value or --index is given, only the matching occurrence of a multi-value key is deleted.",
+		Args:         cobra.RangeArgs(2, 3),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return editSystemIniFile(args[0], false, encoding, func(iniFile *ini.GenericIniFile) error {
+				section, key, err := parseKeyAddress(args[1])
+				if err != nil {
+					return err
+				}
+
+				switch {
+				case len(args) == 3:
+					value := args[2]
+					if !iniFile.DeleteUniqueKey(section, key, &value, nil) {
+						return fmt.Errorf("value not found: [%s].%s=%s", section, key, value)
+					}
+				case cmd.Flags().Changed("index"):
+					keyIndex, ok := findKeyIndex(iniFile, section, key, index)
+					if !ok || !iniFile.DeleteUniqueKey(section, key, nil, &keyIndex) {
+						return fmt.Errorf("index %d not found: [%s].%s", index, section, key)
+					}
+				default:
+					if !iniFile.DeleteKey(section, key) {
+						return fmt.Errorf("key not found: [%s].%s", section, key)
+					}
+				}
+				return nil
+			})
+		},
+	}
+	delCmd.Flags().IntVar(&index, "index", 0, "zero-based occurrence of the key to delete")
+
+	lintCmd := &cobra.Command{
+		Use:          "lint <file>",
+		Short:        "Report the malformed lines of an ini file",
+		Long:         "Parse an ini file strictly and report the duplicate sections, the invalid lines and the keys found outside of a section, with their line numbers.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, _, err := loadSystemIniFile(args[0], false, ini.ParseStrict, encoding); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "No issue found")
+			return nil
+		},
+	}
+
+	iniCmd.PersistentFlags().StringVar(&encoding, "encoding", "", "file encoding (utf-8, utf-8-bom, utf-16le or utf-16be), detected if empty")
+
+	iniCmd.AddCommand(getCmd, setCmd, addCmd, delCmd, lintCmd)
+	return iniCmd
+}
+
+// parseKeyAddress splits a 'Section.Key' address. Section names may contain dots,
+// so the key is everything after the last one.
+func parseKeyAddress(address string) (string, string, error) {
+	idx := strings.LastIndex(address, ".")
+	if idx <= 0 || idx == len(address)-1 {
+		return "", "", fmt.Errorf("invalid key address '%s': expected 'Section.Key'", address)
+	}
+	return address[:idx], address[idx+1:], nil
+}
+
+// findKeyIndex returns the position within its section of the n-th occurrence of a key.
+func findKeyIndex(iniFile *ini.GenericIniFile, section string, key string, n int) (int, bool) {
+	sect := iniFile.GetSection(section)
+	if sect == nil {
+		return 0, false
+	}
+
+	count := 0
+	for _, k := range sect.Keys() {
+		if k.HasName(key) {
+			if count == n {
+				return k.Index, true
+			}
+			count++
+		}
+	}
+	return 0, false
+}
+
+func loadSystemIniFile(fileName string, create bool, mode ini.ParseMode, encoding string) (*ini.GenericIniFile, string, error) {
+	if !filepath.IsLocal(fileName) {
+		return nil, "", fmt.Errorf("invalid file '%s': must be relative to the System directory", fileName)
+	}
+
+	filePath := filepath.Join(viper.GetString("steamcmd-appinstalldir"), "System", fileName)
+	iniFile := ini.NewGenericIniFile(filepath.Base(fileName))
+	iniFile.SetParseMode(mode)
+	if encoding != "" {
+		enc, err := ini.ParseEncoding(encoding)
+		if err != nil {
+			return nil, "", err
+		}
+		iniFile.SetEncoding(enc)
+	}
+
+	if !utils.FileExists(filePath) {
+		if create {
+			return iniFile, filePath, nil
+		}
+		return nil, "", fmt.Errorf("file not found: %s", filePath)
+	}
+
+	if err := iniFile.Load(filePath); err != nil {
+		return nil, "", err
+	}
+	return iniFile, filePath, nil
+}
+
+func editSystemIniFile(fileName string, create bool, encoding string, edit func(iniFile *ini.GenericIniFile) error) error {
+	iniFile, filePath, err := loadSystemIniFile(fileName, create, ini.ParseLenient, encoding)
+	if err != nil {
+		return err
+	}
+
+	if err := edit(iniFile); err != nil {
+		return err
+	}
+	return iniFile.Save(filePath)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+func BuildInstallCommand(install func(sett *settings.KFDSLSettings, validate bool) error) *cobra.Command {
+	return &cobra.Command{
+		Use:          "install",
+		Short:        "Install the server files using SteamCMD",
+		Long:         "Install the server files using SteamCMD and copy the Steam libraries into the server directory, without starting the server. Files are validated unless --novalidate is set.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sett := settings.Get()
+			return install(sett, !sett.NoValidate.Value())
+		},
+	}
+}
+
+func BuildUpdateCommand(update func(sett *settings.KFDSLSettings, validate bool) error) *cobra.Command {
+	var validate bool
+
+	updateCmd := &cobra.Command{
+		Use:          "update",
+		Short:        "Update the server files using SteamCMD",
+		Long:         "Update the server files using SteamCMD and copy the Steam libraries into the server directory, without starting the server.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return update(settings.Get(), validate)
+		},
+	}
+	updateCmd.Flags().BoolVar(&validate, "validate", false, "check the server files integrity")
+	return updateCmd
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/config"
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/services/kfserver"
+	"github.com/K4rian/kfdsl/internal/settings"
+	"github.com/K4rian/kfdsl/internal/utils"
+)
+
+type gameModeMaps struct {
+	GameMode       string         `json:"gameMode"`
+	Prefixes       []string       `json:"prefixes"`
+	MaplistSection string         `json:"maplistSection"`
+	Maps           []installedMap `json:"maps"`
+}
+
+type installedMap struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	ModTime   time.Time `json:"modTime"`
+	InMaplist bool      `json:"inMaplist"`
+}
+
+func BuildMapsCommand() *cobra.Command {
+	var jsonOutput bool
+
+	mapsCmd := &cobra.Command{
+		Use:   "maps",
+		Short: "List the installed maps per game mode",
+		Long:  "List every installed map per game mode, along with its size, modification time and whether it is part of the configuration file maplist.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modes, err := listInstalledMaps(settings.Get())
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(modes)
+			}
+			return printInstalledMaps(cmd.OutOrStdout(), modes)
+		},
+	}
+	mapsCmd.Flags().BoolVar(&jsonOutput, "json", false, "output as JSON")
+	return mapsCmd
+}
+
+func listInstalledMaps(sett *settings.KFDSLSettings) ([]gameModeMaps, error) {
+	rootDir := viper.GetString("steamcmd-appinstalldir")
+	mapsDir := filepath.Join(rootDir, "Maps")
+	configFilePath := filepath.Join(rootDir, "System", sett.ConfigFile.Value())
+	serverGameMode := kfserver.GetGameModeOrDefault(sett.GameMode.Value())
+
+	// The maplist column is left empty if the configuration file can't be read
+	var iniFile config.ServerIniFile
+	if utils.FileExists(configFilePath) {
+		var err error
+		if iniFile, err = config.NewServerIniFile(serverGameMode.IniType, configFilePath, serverGameMode.GameSection); err != nil {
+			log.Logger.Warn("Unable to read the server configuration file",
+				"function", "listInstalledMaps", "file", configFilePath, "error", err)
+		}
+	}
+
+	ret := []gameModeMaps{}
+	for _, gameMode := range kfserver.GetGameModes() {
+		modeMaps := gameModeMaps{
+			GameMode:       gameMode,
+			Prefixes:       kfserver.GetGameModeMapPrefixes(gameMode),
+			MaplistSection: kfserver.GetGameModeMaplistName(gameMode),
+			Maps:           []installedMap{},
+		}
+
+		mapFiles, err := kfserver.GetInstalledMapFiles(mapsDir, modeMaps.Prefixes...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch installed maps for game mode '%s': %w", gameMode, err)
+		}
+
+		var maplist []string
+		if iniFile != nil {
+			for _, m := range iniFile.GetMaplist(modeMaps.MaplistSection) {
+				maplist = append(maplist, strings.ToLower(m))
+			}
+		}
+
+		for _, mapFile := range mapFiles {
+			modeMaps.Maps = append(modeMaps.Maps, installedMap{
+				Name:      mapFile.Name,
+				Size:      mapFile.Size,
+				ModTime:   mapFile.ModTime,
+				InMaplist: slices.Contains(maplist, strings.ToLower(mapFile.Name)),
+			})
+		}
+		ret = append(ret, modeMaps)
+	}
+	return ret, nil
+}
+
+func printInstalledMaps(out io.Writer, modes []gameModeMaps) error {
+	for i, mode := range modes {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s (prefix: %s, maplist: [%s]) - %d map(s)\n",
+			mode.GameMode, strings.Join(mode.Prefixes, ", "), mode.MaplistSection, len(mode.Maps))
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tMODIFIED\tIN MAPLIST")
+		for _, m := range mode.Maps {
+			inMaplist := "no"
+			if m.InMaplist {
+				inMaplist = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				m.Name, utils.FormatSize(m.Size), m.ModTime.Format(time.DateTime), inMaplist)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+func BuildRenderCommand(render func(sett *settings.KFDSLSettings, out io.Writer) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   "render",
+		Short: "Preview the configuration changes",
+		Long:  "Apply the settings to a scratch copy of the configuration files and print a unified diff, without running SteamCMD or the server.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return render(settings.Get(), cmd.OutOrStdout())
+		},
+	}
+}
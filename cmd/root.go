@@ -1,38 +1,46 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	"github.com/K4rian/kfdsl/internal/arguments"
+	"github.com/K4rian/kfdsl/internal/config/secrets"
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/services/kfserver"
 	"github.com/K4rian/kfdsl/internal/settings"
 )
 
 func BuildRootCommand() *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:   "./kfdsl",
-		Short: "KF Dedicated Server Launcher",
-		Long:  "A command-line tool to configure and run a Killing Floor Dedicated Server.",
-		RunE:  runRootCommand,
+		Use:               "./kfdsl",
+		Short:             "KF Dedicated Server Launcher",
+		Long:              "A command-line tool to configure and run a Killing Floor Dedicated Server.",
+		Args:              cobra.ArbitraryArgs,
+		PersistentPreRunE: loadSettings,
+		RunE:              runRootCommand,
 	}
 
 	var userHome, _ = os.UserHomeDir()
 
-	var configFile, serverName, shortName, gameMode, startupMap, gameDifficulty, gameLength,
-		password, adminName, adminMail, adminPassword, motd, specimenType, mutators,
+	var launcherConfig, profileFile, profile, driftPolicy, secretsDir, secretsFile, secretsStore, secretsStoreKeyFile, secretsOrder, configFile, serverName, shortName, gameMode, startupMap, gameDifficulty, gameLength,
+		password, adminName, adminMail, adminPassword, motd, specimenType, specimenCalendar, mutators,
 		serverMutators, redirectURL, mapList, allTradersMessage, kfunflectURL, kfpatcherURL,
 		logLevel, logFilePath, logFileFormat, steamRootDir, steamAppInstallDir string
 
-	var gamePort, webadminPort, gamespyPort, maxPlayers, maxSpectators, region,
+	var reloadWaitTimeout, gamePort, webadminPort, gamespyPort, maxPlayers, maxSpectators, region,
 		mapVoteRepeatLimit, logMaxSize, logMaxBackups, logMaxAge int
 
 	var friendlyFire float64
 
-	var enableWebAdmin, enableMapVote, enableAdminPause, disableWeaponThrow,
+	var reloadWaitEmpty, enableWebAdmin, enableMapVote, enableAdminPause, disableWeaponThrow,
 		disableWeaponShake, enableThirdPerson, enableLowGore, uncap, unsecure, noSteam,
 		disableValidation, enableAutoRestart, enableMutloader, enableKFPatcher, enableShowPerks,
 		disableZEDTime, enableBuyEverywhere, enableAllTraders, enableFileLogging bool
@@ -42,6 +50,17 @@ func BuildRootCommand() *cobra.Command {
 		Desc    string
 		Default interface{}
 	}{
+		"launcher-config":        {&launcherConfig, "launcher configuration file (YAML, TOML or JSON) using the flag names as keys", ""},
+		"profile-file":           {&profileFile, "settings profiles file (YAML, TOML or JSON)", ""},
+		"profile":                {&profile, "comma-separated profiles to apply, later ones override earlier ones", ""},
+		"secrets-dir":            {&secretsDir, "directory holding one file per secret", secrets.DefaultDir},
+		"secrets-file":           {&secretsFile, "file holding the secrets as 'name=value' lines", ""},
+		"secrets-store":          {&secretsStore, "encrypted secrets store file", ""},
+		"secrets-store-key-file": {&secretsStoreKeyFile, "file holding the base64-encoded key of the secrets store", ""},
+		"secrets-order":          {&secretsOrder, "secret providers lookup order, e.g. 'dir,env;steamacc_password=store'", secrets.DefaultOrder},
+		"reload-wait-empty":      {&reloadWaitEmpty, "on SIGHUP, wait until the server is empty before restarting it", false},
+		"reload-wait-timeout":    {&reloadWaitTimeout, "maximum time to wait for the server to be empty (minutes, 0 for no limit)", 0},
+		"drift-policy":           {&driftPolicy, "what to do with the ini values changed outside of the launcher: keep, overwrite or fail", "overwrite"},
 		"config":                 {&configFile, "configuration file", settings.DefaultConfigFile},
 		"servername":             {&serverName, "server name", settings.DefaultServerName},
 		"shortname":              {&shortName, "server short name", settings.DefaultShortName},
@@ -61,7 +80,8 @@ func BuildRootCommand() *cobra.Command {
 		"adminmail":              {&adminMail, "server administrator email", settings.DefaultAdminMail},
 		"adminpassword":          {&adminPassword, "server administrator password", settings.DefaultAdminPassword},
 		"motd":                   {&motd, "message of the day", settings.DefaultMOTD},
-		"specimentype":           {&specimenType, "specimen type (default, summer, halloween, christmas)", settings.DefaultSpecimenType},
+		"specimentype":           {&specimenType, "specimen type (default, summer, halloween, christmas, auto)", settings.DefaultSpecimenType},
+		"specimen-calendar":      {&specimenCalendar, "seasonal events used by the 'auto' specimen type ('MM-DD..MM-DD=ET_Type', comma-separated)", kfserver.DefaultSpecimenCalendar},
 		"mutators":               {&mutators, "comma-separated mutators (command-line)", settings.DefaultMutators},
 		"servermutators":         {&serverMutators, "comma-separated mutators (server actors)", settings.DefaultServerMutators},
 		"redirecturl":            {&redirectURL, "redirect URL", settings.DefaultRedirectURL},
@@ -103,16 +123,16 @@ func BuildRootCommand() *cobra.Command {
 		switch v := data.Default.(type) {
 		case string:
 			val := data.Value.(*string)
-			rootCmd.Flags().StringVar(val, flag, v, data.Desc)
+			rootCmd.PersistentFlags().StringVar(val, flag, v, data.Desc)
 		case int:
 			val := data.Value.(*int)
-			rootCmd.Flags().IntVar(val, flag, v, data.Desc)
+			rootCmd.PersistentFlags().IntVar(val, flag, v, data.Desc)
 		case float64:
 			val := data.Value.(*float64)
-			rootCmd.Flags().Float64Var(val, flag, v, data.Desc)
+			rootCmd.PersistentFlags().Float64Var(val, flag, v, data.Desc)
 		case bool:
 			val := data.Value.(*bool)
-			rootCmd.Flags().BoolVar(val, flag, v, data.Desc)
+			rootCmd.PersistentFlags().BoolVar(val, flag, v, data.Desc)
 		}
 
 		// SteamCMD-related configurations don't use the 'KF' prefix
@@ -122,15 +142,11 @@ func BuildRootCommand() *cobra.Command {
 		} else {
 			viper.BindEnv(flag)
 		}
-		viper.BindPFlag(flag, rootCmd.Flags().Lookup(flag))
+		viper.BindPFlag(flag, rootCmd.PersistentFlags().Lookup(flag))
 	}
 
-	viper.BindEnv("STEAMACC_USERNAME")
-	viper.BindEnv("STEAMACC_PASSWORD")
 	viper.BindEnv("KF_EXTRAARGS")
 
-	viper.SetDefault("STEAMACC_USERNAME", settings.DefaultSteamLogin)
-
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.SetEnvPrefix("KF")
 	viper.AutomaticEnv()
@@ -138,12 +154,145 @@ func BuildRootCommand() *cobra.Command {
 	return rootCmd
 }
 
-func runRootCommand(cmd *cobra.Command, args []string) error {
+// envName returns the prefixed environment variable name of a flag.
+func envName(flag string) string {
+	name := strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+
+	// SteamCMD-related configurations don't use the 'KF' prefix
+	if strings.HasPrefix(flag, "steamcmd") {
+		return name
+	}
+	return "KF_" + name
+}
+
+// Custom game modes read by loadSettings, registered by the root command once the settings are validated
+var pendingGameModes []kfserver.GameMode
+
+// Configuration values of the settings in use, put back into viper when a reload isn't committed
+var committedConfig map[string]any
+
+// loadSettings parses the settings and initializes the logger.
+// It runs before the root command and every subcommand.
+func loadSettings(cmd *cobra.Command, args []string) error {
 	sett := settings.Get()
 
-	registerArguments(sett)
+	config, gameModes, err := readSettings(cmd.Root().PersistentFlags(), sett)
+	if err != nil {
+		return err
+	}
+	committedConfig = config
 
-	if err := sett.Parse(); err != nil {
+	// The subcommands don't validate the settings
+	if cmd == cmd.Root() {
+		pendingGameModes = gameModes
+	} else if err := registerGameModes(gameModes); err != nil {
+		return err
+	}
+
+	log.Init(
+		sett.LogLevel.Value(),
+		sett.LogFile.Value(),
+		sett.LogFileFormat.Value(),
+		sett.LogMaxSize.Value(),
+		sett.LogMaxBackups.Value(),
+		sett.LogMaxAge.Value(),
+		sett.LogToFile.Value(),
+	)
+	log.Logger.Debug("Log system initialized",
+		"function", "loadSettings", "command", cmd.Name())
+	return nil
+}
+
+// ReloadSettings reads the configuration files, environment variables and flags again and returns
+// the new settings, along with a function replacing the current ones and registering the new game modes.
+// Nothing is changed until that function is called, nor at all if the new settings are invalid.
+func ReloadSettings(rootCmd *cobra.Command) (*settings.KFDSLSettings, func() error, error) {
+	sett := &settings.KFDSLSettings{}
+	config, gameModes, err := readSettings(rootCmd.PersistentFlags(), sett)
+	if err == nil {
+		err = validateSettings(sett, gameModes)
+	}
+
+	// viper keeps serving the current configuration until the new one is committed
+	if restoreErr := setConfig(committedConfig); restoreErr != nil {
+		return nil, nil, restoreErr
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Runtime values aren't read from the configuration sources
+	current := settings.Get()
+	sett.ExtraArgs = current.ExtraArgs
+	sett.SteamLogin = current.SteamLogin
+	sett.SteamPassword = current.SteamPassword
+
+	commit := func() error {
+		if err := registerGameModes(gameModes); err != nil {
+			return err
+		}
+		if err := setConfig(config); err != nil {
+			return err
+		}
+		committedConfig = config
+		*current = *sett
+		return nil
+	}
+	return sett, commit, nil
+}
+
+// readSettings reads and parses the settings. It returns the configuration values it put into viper,
+// and the custom game modes of the launcher configuration file, which are not registered yet.
+func readSettings(flags *pflag.FlagSet, sett *settings.KFDSLSettings) (map[string]any, []kfserver.GameMode, error) {
+	// The configuration files and secrets are merged apart from viper, then replace its previous values
+	config := viper.New()
+
+	gameModes, err := readLauncherConfig(flags, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The profiles may be selected by the launcher configuration file
+	if err := setConfig(config.AllSettings()); err != nil {
+		return nil, nil, err
+	}
+	if err := applyProfiles(flags, config); err != nil {
+		return nil, nil, err
+	}
+
+	if err := readSecretFiles(flags, config); err != nil {
+		return nil, nil, err
+	}
+
+	values := config.AllSettings()
+	if err := setConfig(values); err != nil {
+		return nil, nil, err
+	}
+
+	registerArguments(sett, gameModes)
+
+	return values, gameModes, sett.Parse()
+}
+
+// setConfig replaces the configuration values of viper, so removed keys don't linger.
+// Flags and environment variables still take precedence over them.
+func setConfig(values map[string]any) error {
+	viper.SetConfigType("json")
+	if err := viper.ReadConfig(strings.NewReader("{}")); err != nil {
+		return err
+	}
+	return viper.MergeConfigMap(values)
+}
+
+func runRootCommand(cmd *cobra.Command, args []string) error {
+	sett := settings.Get()
+
+	// Only the server needs consistent settings, the other commands must work to diagnose them
+	if err := validateSettings(sett, pendingGameModes); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	if err := registerGameModes(pendingGameModes); err != nil {
 		return err
 	}
 
@@ -152,14 +301,16 @@ func runRootCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func registerArguments(sett *settings.KFDSLSettings) {
+// registerArguments creates the settings arguments from viper.
+// The game mode is resolved from the registered game modes and the given ones.
+func registerArguments(sett *settings.KFDSLSettings, gameModes []kfserver.GameMode) {
 	sett.ConfigFile = arguments.NewArgument("Config File", viper.GetString("config"), nil, nil, false)
 	sett.ServerName = arguments.NewArgument("Server Name", viper.GetString("servername"), arguments.ParseNonEmptyStr, nil, false)
 	sett.ShortName = arguments.NewArgument("Short Name", viper.GetString("shortname"), arguments.ParseNonEmptyStr, nil, false)
 	sett.GamePort = arguments.NewArgument("Game Port", viper.GetInt("port"), arguments.ParsePort, nil, false)
 	sett.WebAdminPort = arguments.NewArgument("WebAdmin Port", viper.GetInt("webadminport"), arguments.ParsePort, nil, false)
 	sett.GameSpyPort = arguments.NewArgument("GameSpy Port", viper.GetInt("gamespyport"), arguments.ParsePort, nil, false)
-	sett.GameMode = arguments.NewArgument("Game Mode", viper.GetString("gamemode"), arguments.ParseGameMode, arguments.FormatGameMode, false)
+	sett.GameMode = arguments.NewArgument("Game Mode", viper.GetString("gamemode"), nil, arguments.FormatGameMode, false)
 	sett.StartupMap = arguments.NewArgument("Startup Map", viper.GetString("map"), arguments.ParseNonEmptyStr, nil, false)
 	sett.GameDifficulty = arguments.NewArgument("Game Difficulty", settings.DefaultInternalGameDifficulty, arguments.ParseGameDifficulty(viper.GetString("difficulty")), arguments.FormatGameDifficulty, false)
 	sett.GameLength = arguments.NewArgument("Game Length", settings.DefaultInternalGameLength, arguments.ParseGameLength(viper.GetString("length")), arguments.FormatGameLength, false)
@@ -172,7 +323,8 @@ func registerArguments(sett *settings.KFDSLSettings) {
 	sett.AdminMail = arguments.NewArgument("Admin Mail", viper.GetString("adminmail"), arguments.ParseMail, nil, true)
 	sett.AdminPassword = arguments.NewArgument("Admin Password", viper.GetString("adminpassword"), arguments.ParsePassword, nil, true)
 	sett.MOTD = arguments.NewArgument("MOTD", viper.GetString("motd"), nil, nil, false)
-	sett.SpecimenType = arguments.NewArgument("Specimens Type", viper.GetString("specimentype"), arguments.ParseSpecimenType, arguments.FormatSpecimenType, false)
+	sett.SpecimenType = arguments.NewArgument("Specimens Type", viper.GetString("specimentype"), nil, arguments.FormatSpecimenType, false)
+	sett.SpecimenCalendar = arguments.NewArgument("Specimens Calendar", viper.GetString("specimen-calendar"), nil, nil, false)
 	sett.Mutators = arguments.NewArgument("Mutators", viper.GetString("mutators"), nil, nil, false)
 	sett.ServerMutators = arguments.NewArgument("Server Mutators", viper.GetString("servermutators"), nil, nil, false)
 	sett.RedirectURL = arguments.NewArgument("Redirect URL", viper.GetString("redirecturl"), arguments.ParseURL, nil, false)
@@ -208,5 +360,23 @@ func registerArguments(sett *settings.KFDSLSettings) {
 	sett.LogMaxAge = arguments.NewArgument("Log Max Age (days)", viper.GetInt("log-max-age"), arguments.ParsePositiveInt, nil, false)
 
 	sett.MaxPlayers.SetParserFunction(arguments.ParseIntRange(sett.MaxPlayers, 0, 32))
+	sett.GameMode.SetParserFunction(arguments.ParseGameMode(func(name string) (string, bool) {
+		mode, ok := kfserver.LookupGameMode(name, gameModes)
+		return mode.Class, ok
+	}))
+	sett.SpecimenCalendar.SetParserFunction(func(a *arguments.Argument[string]) (string, error) {
+		if _, err := kfserver.ParseSpecimenCalendar(a.RawValue()); err != nil {
+			return "", fmt.Errorf("invalid %s: %w", a.Name(), err)
+		}
+		return a.RawValue(), nil
+	})
+	// The 'auto' specimen type is resolved from the calendar each time the setting is parsed
+	sett.SpecimenType.SetParserFunction(arguments.ParseAutoSpecimenType(func() (string, error) {
+		calendar, err := kfserver.ParseSpecimenCalendar(sett.SpecimenCalendar.RawValue())
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %w", sett.SpecimenCalendar.Name(), err)
+		}
+		return kfserver.GetSeasonalSpecimenType(calendar, time.Now()), nil
+	}))
 	sett.MaxSpectators.SetParserFunction(arguments.ParseIntRange(sett.MaxSpectators, 0, 32))
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/config/secrets"
+)
+
+func BuildSecretsCommand() *cobra.Command {
+	secretsCmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the encrypted secrets store",
+		Long: "Manage the encrypted secrets store set by --secrets-store, using the key read from --secrets-store-key-file.\n" +
+			"A key can be generated with 'head -c 32 /dev/urandom | base64'.",
+	}
+
+	setCmd := &cobra.Command{
+		Use:          "set <name>",
+		Short:        "Store a secret, reading its value from the standard input",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := io.ReadAll(cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			value := strings.TrimRight(string(data), "\r\n")
+			if value == "" {
+				return fmt.Errorf("empty value for secret '%s'", args[0])
+			}
+
+			return editSecretsStore(func(values map[string]string) error {
+				values[strings.ToLower(args[0])] = value
+				return nil
+			})
+		},
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:          "delete <name>",
+		Short:        "Remove a secret",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return editSecretsStore(func(values map[string]string) error {
+				name := strings.ToLower(args[0])
+				if _, ok := values[name]; !ok {
+					return fmt.Errorf("secret not found: %s", name)
+				}
+				delete(values, name)
+				return nil
+			})
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:          "list",
+		Short:        "List the names of the stored secrets",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openSecretsStore()
+			if err != nil {
+				return err
+			}
+
+			values, err := store.Load()
+			if err != nil {
+				return err
+			}
+
+			names := make([]string, 0, len(values))
+			for name := range values {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				fmt.Fprintln(cmd.OutOrStdout(), name)
+			}
+			return nil
+		},
+	}
+
+	secretsCmd.AddCommand(setCmd, deleteCmd, listCmd)
+	return secretsCmd
+}
+
+func openSecretsStore() (*secrets.Store, error) {
+	storePath := viper.GetString("secrets-store")
+	if storePath == "" {
+		return nil, fmt.Errorf("no secrets store was given, use --secrets-store")
+	}
+	return secrets.OpenStore(storePath, viper.GetString("secrets-store-key-file"))
+}
+
+func editSecretsStore(edit func(values map[string]string) error) error {
+	store, err := openSecretsStore()
+	if err != nil {
+		return err
+	}
+
+	values, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := edit(values); err != nil {
+		return err
+	}
+	return store.Save(values)
+}
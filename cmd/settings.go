@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+const redactedValue = "<redacted>"
+
+// Values that can be written unquoted in an env file
+var envSafeValue = regexp.MustCompile(`^[A-Za-z0-9_./:,@+-]*$`)
+
+func BuildSettingsCommand() *cobra.Command {
+	settingsCmd := &cobra.Command{
+		Use:   "settings",
+		Short: "Inspect the launcher settings",
+	}
+
+	var format string
+
+	exportCmd := &cobra.Command{
+		Use:          "export",
+		Short:        "Export the effective settings",
+		Long:         "Export the effective settings, resolved from the flags, environment variables and defaults, as parsed by the launcher. The env format keeps the values as given, so it can be sourced back. Sensitive values are redacted.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Root().PersistentFlags()
+			sett := settings.Get()
+			out := cmd.OutOrStdout()
+
+			switch strings.ToLower(format) {
+			case "json":
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(effectiveSettings(flags, sett, false))
+			case "yaml":
+				encoder := yaml.NewEncoder(out)
+				encoder.SetIndent(2)
+				if err := encoder.Encode(effectiveSettings(flags, sett, false)); err != nil {
+					return err
+				}
+				return encoder.Close()
+			case "env":
+				return writeEnvSettings(out, effectiveSettings(flags, sett, true))
+			default:
+				return fmt.Errorf("invalid format '%s': expected json, yaml or env", format)
+			}
+		},
+	}
+	exportCmd.Flags().StringVar(&format, "format", "json", "output format (json, yaml, env)")
+
+	settingsCmd.AddCommand(exportCmd)
+	return settingsCmd
+}
+
+// effectiveSettings returns the value of every launcher flag, keyed by flag name.
+// The settings take their parsed value, or the value given to their flag if raw is set.
+// The flags without a setting, such as the SteamCMD ones, always take the given value.
+func effectiveSettings(flags *pflag.FlagSet, sett *settings.KFDSLSettings, raw bool) map[string]any {
+	args := map[string]settings.FlagArgument{}
+	for _, arg := range sett.Arguments() {
+		args[arg.Flag] = arg
+	}
+
+	values := map[string]any{}
+	flags.VisitAll(func(f *pflag.Flag) {
+		arg, isSetting := args[f.Name]
+
+		var value any
+		switch f.Value.Type() {
+		case "string":
+			value = viper.GetString(f.Name)
+		case "int":
+			value = viper.GetInt(f.Name)
+		case "float64":
+			value = viper.GetFloat64(f.Name)
+		case "bool":
+			value = viper.GetBool(f.Name)
+		default:
+			value = viper.Get(f.Name)
+		}
+		if isSetting && !raw {
+			value = arg.Value
+		}
+
+		if isSetting && arg.IsSensitive() && value != "" {
+			value = redactedValue
+		}
+		values[f.Name] = value
+	})
+	return values
+}
+
+// writeEnvSettings writes the settings as an env file that can be sourced back.
+// Redacted values are commented out.
+func writeEnvSettings(out io.Writer, values map[string]any) error {
+	flags := make([]string, 0, len(values))
+	for flag := range values {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	for _, flag := range flags {
+		value := fmt.Sprintf("%v", values[flag])
+
+		line := fmt.Sprintf("%s=%s", envName(flag), quoteEnvValue(value))
+		if value == redactedValue {
+			line = "# " + line
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func quoteEnvValue(value string) string {
+	if envSafeValue.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
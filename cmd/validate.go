@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/config"
+	"github.com/K4rian/kfdsl/internal/services/kfserver"
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+// validateSettings checks the relationships between the parsed settings
+// and reports every problem found at once. The game modes not registered yet are taken into account.
+func validateSettings(sett *settings.KFDSLSettings, gameModes []kfserver.GameMode) error {
+	var errs []error
+
+	// Ports
+	ports := []struct {
+		name string
+		port int
+	}{
+		{"game port", sett.GamePort.Value()},
+		{"query port (game port + 1)", sett.GamePort.Value() + 1},
+		{"GameSpy port", sett.GameSpyPort.Value()},
+	}
+	if sett.EnableWebAdmin.Value() {
+		ports = append(ports, struct {
+			name string
+			port int
+		}{"WebAdmin port", sett.WebAdminPort.Value()})
+	}
+	for i := range ports {
+		for j := i + 1; j < len(ports); j++ {
+			if ports[i].port == ports[j].port {
+				errs = append(errs, fmt.Errorf("the %s and the %s are both set to %d", ports[i].name, ports[j].name, ports[i].port))
+			}
+		}
+	}
+
+	// Startup map
+	gameMode := sett.GameMode.RawValue()
+	mode, _ := kfserver.LookupGameMode(gameMode, gameModes)
+	startupMap := sett.StartupMap.Value()
+	if !mode.HasMapPrefix(startupMap) {
+		errs = append(errs, fmt.Errorf("the startup map '%s' doesn't match the '%s' game mode, its name must start with '%s'",
+			startupMap, gameMode, strings.Join(mode.MapPrefixes, "' or '")))
+	}
+
+	// Toy Master, chosen by name or class, or any game mode using its ini file
+	// Only explicit values are rejected, the defaults are forced to the supported ones when writing the ini file
+	if mode.IniType == config.IniTypeToyGame {
+		if viper.IsSet("length") && sett.GameLength.Value() != 0 {
+			errs = append(errs, fmt.Errorf("Toy Master only supports the short game length, got '%s'", sett.GameLength.FormattedValue()))
+		}
+		if viper.IsSet("maxplayers") && sett.MaxPlayers.Value() != 6 {
+			errs = append(errs, fmt.Errorf("Toy Master only supports 6 players, got %d", sett.MaxPlayers.Value()))
+		}
+		if viper.IsSet("specimentype") && !strings.EqualFold(sett.SpecimenType.RawValue(), "auto") && sett.SpecimenType.Value() != settings.DefaultInternalSpecimenType {
+			errs = append(errs, fmt.Errorf("Toy Master only supports the default specimen type, got '%s'", sett.SpecimenType.FormattedValue()))
+		}
+		if viper.IsSet("mapvote") && sett.EnableMapVote.Value() {
+			errs = append(errs, fmt.Errorf("map voting is not available in Toy Master, as only one map is available"))
+		}
+	}
+
+	// Mutators
+	if sett.EnableMutLoader.Value() && strings.TrimSpace(sett.Mutators.Value()) != "" {
+		errs = append(errs, fmt.Errorf("the mutators '%s' would be discarded as MutLoader is enabled, use the server mutators or the MutLoader configuration instead", sett.Mutators.Value()))
+	}
+
+	// WebAdmin
+	if sett.EnableWebAdmin.Value() && sett.AdminPassword.Value() == "" {
+		errs = append(errs, fmt.Errorf("WebAdmin is enabled but no admin password is set"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid settings:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"debug/elf"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/config/ini"
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/settings"
+	"github.com/K4rian/kfdsl/internal/utils"
+)
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "PASS"
+	checkWarn checkStatus = "WARN"
+	checkFail checkStatus = "FAIL"
+)
+
+type checkResult struct {
+	name    string
+	status  checkStatus
+	message string
+	hint    string // Remediation hint, displayed on warning or failure
+}
+
+// runDoctor checks the environment the launcher relies on, writes a report to out
+// and returns an error if any check failed.
+func runDoctor(sett *settings.KFDSLSettings, out io.Writer, minFreeSpaceMB int) error {
+	steamRoot := viper.GetString("steamcmd-root")
+	serverRoot := viper.GetString("steamcmd-appinstalldir")
+
+	log.Logger.Debug("Running diagnostics",
+		"function", "runDoctor", "steamRoot", steamRoot, "serverRoot", serverRoot)
+
+	results := []checkResult{
+		checkSteamCMD(sett, steamRoot),
+		checkServerBinary(serverRoot),
+		checkSteamLibraries(steamRoot),
+		checkInstallDirWritable(serverRoot),
+		checkConfigFile(serverRoot, sett.ConfigFile.Value()),
+		checkStartupMap(serverRoot, sett.StartupMap.Value()),
+		checkFreeSpace(serverRoot, minFreeSpaceMB),
+	}
+
+	failed := 0
+	for _, res := range results {
+		fmt.Fprintf(out, "[%s] %s: %s\n", res.status, res.name, res.message)
+		if res.status != checkPass && res.hint != "" {
+			fmt.Fprintf(out, "       → %s\n", res.hint)
+		}
+		if res.status == checkFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func checkSteamCMD(sett *settings.KFDSLSettings, steamRoot string) checkResult {
+	res := checkResult{name: "SteamCMD"}
+	scriptPath := filepath.Join(steamRoot, "steamcmd.sh")
+
+	// SteamCMD is only required when it's not bypassed
+	failStatus := checkFail
+	if sett.NoSteam.Value() {
+		failStatus = checkWarn
+	}
+
+	info, err := os.Stat(scriptPath)
+	switch {
+	case err != nil:
+		res.status = failStatus
+		res.message = fmt.Sprintf("%s not found", scriptPath)
+		res.hint = "install SteamCMD or set --steamcmd-root to its directory"
+	case info.Mode()&0111 == 0:
+		res.status = failStatus
+		res.message = fmt.Sprintf("%s is not executable", scriptPath)
+		res.hint = fmt.Sprintf("run 'chmod +x %s'", scriptPath)
+	default:
+		res.status = checkPass
+		res.message = fmt.Sprintf("%s is present and executable", scriptPath)
+	}
+	return res
+}
+
+func checkServerBinary(serverRoot string) checkResult {
+	res := checkResult{name: "Server binary", status: checkFail}
+	binPath := filepath.Join(serverRoot, "System", "ucc-bin")
+
+	info, err := os.Stat(binPath)
+	if err != nil {
+		res.message = fmt.Sprintf("%s not found", binPath)
+		res.hint = "install the server files with SteamCMD or set --steamcmd-appinstalldir to the server directory"
+		return res
+	}
+
+	if info.Mode()&0111 == 0 {
+		res.message = fmt.Sprintf("%s is not executable", binPath)
+		res.hint = fmt.Sprintf("run 'chmod +x %s'", binPath)
+		return res
+	}
+
+	elfFile, err := elf.Open(binPath)
+	if err != nil {
+		res.message = fmt.Sprintf("%s is not a valid ELF binary: %v", binPath, err)
+		res.hint = "validate the server files with SteamCMD (remove --novalidate)"
+		return res
+	}
+	defer elfFile.Close()
+
+	if elfFile.Class != elf.ELFCLASS32 {
+		res.message = fmt.Sprintf("%s is not a 32-bit ELF binary (%s)", binPath, elfFile.Class)
+		res.hint = "validate the server files with SteamCMD (remove --novalidate)"
+		return res
+	}
+
+	res.status = checkPass
+	res.message = fmt.Sprintf("%s is a 32-bit ELF binary", binPath)
+	return res
+}
+
+func checkSteamLibraries(steamRoot string) checkResult {
+	res := checkResult{name: "Steam libraries"}
+	libsDir := filepath.Join(steamRoot, "linux32")
+
+	var missingLibs []string
+	for _, lib := range steamLibraries {
+		if !utils.FileExists(filepath.Join(libsDir, lib)) {
+			missingLibs = append(missingLibs, lib)
+		}
+	}
+
+	if len(missingLibs) > 0 {
+		res.status = checkWarn
+		res.message = fmt.Sprintf("missing in %s: %v", libsDir, missingLibs)
+		res.hint = "run SteamCMD once so it downloads its linux32 libraries"
+		return res
+	}
+
+	res.status = checkPass
+	res.message = fmt.Sprintf("all libraries are present in %s", libsDir)
+	return res
+}
+
+func checkInstallDirWritable(serverRoot string) checkResult {
+	res := checkResult{name: "Install directory"}
+
+	if !utils.FileExists(serverRoot) {
+		res.status = checkWarn
+		res.message = fmt.Sprintf("%s does not exist", serverRoot)
+		res.hint = "SteamCMD will create it on install, make sure its parent directory is writable"
+		return res
+	}
+
+	tempFile, err := os.CreateTemp(serverRoot, ".kfdsl-doctor-")
+	if err != nil {
+		res.status = checkFail
+		res.message = fmt.Sprintf("%s is not writable: %v", serverRoot, err)
+		res.hint = "fix the directory ownership or permissions for the user running kfdsl"
+		return res
+	}
+	tempFile.Close()
+	os.Remove(tempFile.Name())
+
+	res.status = checkPass
+	res.message = fmt.Sprintf("%s is writable", serverRoot)
+	return res
+}
+
+func checkConfigFile(serverRoot string, configFileName string) checkResult {
+	res := checkResult{name: "Configuration file"}
+	configFilePath := filepath.Join(serverRoot, "System", configFileName)
+
+	if !utils.FileExists(configFilePath) {
+		res.status = checkWarn
+		res.message = fmt.Sprintf("%s not found", configFilePath)
+		res.hint = "the default configuration file will be extracted on start"
+		return res
+	}
+
+	iniFile := ini.NewGenericIniFile("Doctor")
+	if err := iniFile.Load(configFilePath); err != nil {
+		res.status = checkFail
+		res.message = fmt.Sprintf("%s cannot be parsed: %v", configFilePath, err)
+		res.hint = "fix the reported line or delete the file to restore the default one"
+		return res
+	}
+
+	if warnings := iniFile.Warnings(); len(warnings) > 0 {
+		res.status = checkWarn
+		res.message = fmt.Sprintf("%s has %d parsing issue(s), first: %s", configFilePath, len(warnings), warnings[0])
+		res.hint = fmt.Sprintf("run 'ini lint %s' to list them", configFileName)
+		return res
+	}
+
+	res.status = checkPass
+	res.message = fmt.Sprintf("%s parsed successfully", configFilePath)
+	return res
+}
+
+func checkStartupMap(serverRoot string, startupMap string) checkResult {
+	res := checkResult{name: "Startup map"}
+	mapPath := filepath.Join(serverRoot, "Maps", startupMap+".rom")
+
+	if !utils.FileExists(mapPath) {
+		res.status = checkFail
+		res.message = fmt.Sprintf("%s not found", mapPath)
+		res.hint = "set --map to an installed map (see the 'maps' command)"
+		return res
+	}
+
+	res.status = checkPass
+	res.message = fmt.Sprintf("%s is installed", startupMap)
+	return res
+}
+
+func checkFreeSpace(serverRoot string, minFreeSpaceMB int) checkResult {
+	res := checkResult{name: "Free disk space"}
+
+	// Walk up to the closest existing directory
+	dir := serverRoot
+	for !utils.FileExists(dir) && filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		res.status = checkWarn
+		res.message = fmt.Sprintf("unable to read the free space of %s: %v", dir, err)
+		return res
+	}
+
+	freeSpace := int64(stat.Bavail) * int64(stat.Bsize)
+	if freeSpace < int64(minFreeSpaceMB)*1024*1024 {
+		res.status = checkFail
+		res.message = fmt.Sprintf("%s available on %s, at least %d MB required", utils.FormatSize(freeSpace), dir, minFreeSpaceMB)
+		res.hint = "free some disk space or lower --min-free-space"
+		return res
+	}
+
+	res.status = checkPass
+	res.message = fmt.Sprintf("%s available on %s", utils.FormatSize(freeSpace), dir)
+	return res
+}
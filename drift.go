@@ -0,0 +1,273 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/config"
+	"github.com/K4rian/kfdsl/internal/config/ini"
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/services/kfserver"
+	"github.com/K4rian/kfdsl/internal/settings"
+	"github.com/K4rian/kfdsl/internal/utils"
+)
+
+// What to do with the ini values changed outside of the launcher
+const (
+	DriftPolicyKeep      = "keep"
+	DriftPolicyOverwrite = "overwrite"
+	DriftPolicyFail      = "fail"
+)
+
+// Sensitive values are stored hashed in the snapshot, and never displayed
+const (
+	snapshotHashPrefix = "sha256:"
+	redactedValue      = "<redacted>"
+)
+
+// configSnapshot holds the ini values last written by the launcher, per file name and property,
+// along with a digest of every ini key to merge the changes made on disk meanwhile.
+// The launcher values declined by the keep policy are recorded too, so they are only applied once changed.
+type configSnapshot struct {
+	path     string
+	policy   string
+	Files    map[string]map[string]string `json:"files"`
+	Kept     map[string]map[string]string `json:"kept,omitempty"`
+	Contents map[string]ini.MergeBase     `json:"contents,omitempty"`
+}
+
+// mergeIniFile is an ini file merging the changes made on disk since a given base before saving.
+type mergeIniFile interface {
+	MergeBase() ini.MergeBase
+	SetMergeBase(base ini.MergeBase)
+}
+
+// configDrift is an ini value changed since the launcher wrote it.
+type configDrift struct {
+	File     string
+	Property string
+	Written  string
+	Current  string
+}
+
+func configSnapshotPath(rootDir string) string {
+	return filepath.Join(rootDir, "kfdsl.snapshot.json")
+}
+
+// loadConfigSnapshot reads the snapshot file. A missing file is an empty snapshot.
+func loadConfigSnapshot(filePath string, policy string) (*configSnapshot, error) {
+	switch policy {
+	case DriftPolicyKeep, DriftPolicyOverwrite, DriftPolicyFail:
+	default:
+		return nil, fmt.Errorf("invalid drift policy '%s', expected '%s', '%s' or '%s'", policy, DriftPolicyKeep, DriftPolicyOverwrite, DriftPolicyFail)
+	}
+
+	snapshot := &configSnapshot{path: filePath, policy: policy, Files: map[string]map[string]string{}}
+
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snapshot, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid configuration snapshot '%s': %w", filePath, err)
+	}
+	if snapshot.Files == nil {
+		snapshot.Files = map[string]map[string]string{}
+	}
+	return snapshot, nil
+}
+
+func (s *configSnapshot) Save() error {
+	for fileName, values := range s.Kept {
+		if len(values) == 0 {
+			delete(s.Kept, fileName)
+		}
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0600)
+}
+
+// File returns the snapshot values of an ini file, creating them if needed.
+// A nil snapshot disables the drift detection.
+func (s *configSnapshot) File(fileName string) map[string]string {
+	if s == nil {
+		return nil
+	}
+	if s.Files[fileName] == nil {
+		s.Files[fileName] = map[string]string{}
+	}
+	return s.Files[fileName]
+}
+
+// KeptValues returns the launcher values of an ini file declined by the keep policy, creating them if needed.
+func (s *configSnapshot) KeptValues(fileName string) map[string]string {
+	if s == nil {
+		return nil
+	}
+	if s.Kept == nil {
+		s.Kept = map[string]map[string]string{}
+	}
+	if s.Kept[fileName] == nil {
+		s.Kept[fileName] = map[string]string{}
+	}
+	return s.Kept[fileName]
+}
+
+// Forget drops the snapshot values of an ini file.
+func (s *configSnapshot) Forget(fileName string) {
+	if s != nil {
+		delete(s.Files, fileName)
+		delete(s.Kept, fileName)
+		delete(s.Contents, fileName)
+	}
+}
+
+// SetMergeBase makes an ini file merge the changes made on disk since the launcher last wrote it.
+func (s *configSnapshot) SetMergeBase(iniFile mergeIniFile, fileName string) {
+	if s != nil && s.Contents[fileName] != nil {
+		iniFile.SetMergeBase(s.Contents[fileName])
+	}
+}
+
+// RecordContents records the keys of an ini file the launcher has just written.
+func (s *configSnapshot) RecordContents(iniFile mergeIniFile, fileName string) {
+	if s == nil {
+		return
+	}
+	if s.Contents == nil {
+		s.Contents = map[string]ini.MergeBase{}
+	}
+	s.Contents[fileName] = iniFile.MergeBase()
+}
+
+// findConfigDrifts compares the ini file values to the ones last written by the launcher.
+func findConfigDrifts(iniFile any, fileName string, written map[string]string) []configDrift {
+	var drifts []configDrift
+	for property, writtenValue := range written {
+		getter := config.PropertyGetter(iniFile, property)
+		if !getter.IsValid() {
+			continue
+		}
+
+		if drift, drifted := compareSnapshotValue(fileName, property, writtenValue, getter.Call(nil)[0].Interface()); drifted {
+			drifts = append(drifts, drift)
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Property < drifts[j].Property })
+	return drifts
+}
+
+// snapshotValue returns the snapshot form of an ini value, hashed if sensitive.
+func snapshotValue(value any, sensitive bool) string {
+	str := fmt.Sprint(value)
+	if !sensitive {
+		return str
+	}
+	sum := sha256.Sum256([]byte(str))
+	return snapshotHashPrefix + hex.EncodeToString(sum[:])
+}
+
+func isSensitiveSnapshotValue(value string) bool {
+	return strings.HasPrefix(value, snapshotHashPrefix)
+}
+
+// compareSnapshotValue compares the current value of a property to its snapshot value.
+// The values of the returned drift are redacted if sensitive.
+func compareSnapshotValue(fileName string, property string, writtenValue string, currentValue any) (configDrift, bool) {
+	sensitive := isSensitiveSnapshotValue(writtenValue)
+	if snapshotValue(currentValue, sensitive) == writtenValue {
+		return configDrift{}, false
+	}
+
+	drift := configDrift{File: fileName, Property: property, Written: writtenValue, Current: fmt.Sprint(currentValue)}
+	if sensitive {
+		drift.Written, drift.Current = redactedValue, redactedValue
+	}
+	return drift, true
+}
+
+// iniPropertyValues returns the current snapshot values of the given properties of an ini file.
+func iniPropertyValues(iniFile any, properties map[string]string) map[string]string {
+	values := make(map[string]string, len(properties))
+	for property, writtenValue := range properties {
+		if getter := config.PropertyGetter(iniFile, property); getter.IsValid() {
+			values[property] = snapshotValue(getter.Call(nil)[0].Interface(), isSensitiveSnapshotValue(writtenValue))
+		}
+	}
+	return values
+}
+
+// detectConfigDrift prints the ini values changed since the launcher wrote them,
+// and returns an error if there are any.
+func detectConfigDrift(sett *settings.KFDSLSettings, out io.Writer) error {
+	rootDir := viper.GetString("steamcmd-appinstalldir")
+	systemDir := filepath.Join(rootDir, "System")
+
+	snapshot, err := loadConfigSnapshot(configSnapshotPath(rootDir), viper.GetString("drift-policy"))
+	if err != nil {
+		return err
+	}
+
+	var drifts []configDrift
+
+	configFileName := sett.ConfigFile.Value()
+	if written := snapshot.Files[configFileName]; len(written) > 0 {
+		gameMode := kfserver.GetGameModeOrDefault(sett.GameMode.Value())
+		kfi, err := config.NewServerIniFile(gameMode.IniType, filepath.Join(systemDir, configFileName), gameMode.GameSection)
+		if err != nil {
+			return err
+		}
+		drifts = append(drifts, findConfigDrifts(kfi, configFileName, written)...)
+	}
+
+	kfpConfigFilePath := filepath.Join(systemDir, "KFPatcherSettings.ini")
+	if written := snapshot.Files[filepath.Base(kfpConfigFilePath)]; len(written) > 0 && utils.FileExists(kfpConfigFilePath) {
+		kfpi, err := config.NewKFPIniFile(kfpConfigFilePath)
+		if err != nil {
+			return err
+		}
+		drifts = append(drifts, findConfigDrifts(kfpi, filepath.Base(kfpConfigFilePath), written)...)
+	}
+
+	if len(drifts) == 0 {
+		fmt.Fprintln(out, "No configuration drift")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tPROPERTY\tWRITTEN\tCURRENT")
+	for _, drift := range drifts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", drift.File, drift.Property, drift.Written, drift.Current)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d value(s) changed outside of the launcher", len(drifts))
+}
+
+// logConfigDrift reports a drifted value along with what the drift policy does with it.
+func logConfigDrift(drift configDrift, policy string) {
+	log.Logger.Warn("Configuration value changed outside of the launcher",
+		"file", drift.File, "property", drift.Property, "written", drift.Written, "current", drift.Current, "policy", policy)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/settings"
+	"github.com/K4rian/kfdsl/internal/utils"
+)
+
+// installGameServer installs or updates the server files using SteamCMD
+// and refreshes the server Steam libraries, without starting the server.
+func installGameServer(sett *settings.KFDSLSettings, validate bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	start := time.Now()
+	if err := startSteamCMD(sett, validate, ctx); err != nil {
+		return fmt.Errorf("SteamCMD raised an error: %w", err)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("SteamCMD was interrupted")
+	}
+	log.Logger.Debug("SteamCMD process completed",
+		"function", "installGameServer", "elapsedTime", time.Since(start))
+
+	serverBinary := filepath.Join(viper.GetString("steamcmd-appinstalldir"), "System", "ucc-bin")
+	if !utils.FileExists(serverBinary) {
+		return fmt.Errorf("SteamCMD completed but the KF Dedicated Server files were not found in '%s'", filepath.Dir(filepath.Dir(serverBinary)))
+	}
+
+	log.Logger.Info("Verifying KF Dedicated Server Steam libraries for updates...")
+	updatedLibs, err := updateGameServerSteamLibs()
+	if err != nil {
+		return fmt.Errorf("unable to update the KF Dedicated Server Steam libraries: %w", err)
+	}
+	if len(updatedLibs) > 0 {
+		for _, lib := range updatedLibs {
+			log.Logger.Info("Steam library successfully updated", "library", lib)
+		}
+	} else {
+		log.Logger.Info("All server Steam libraries are up-to-date")
+	}
+
+	log.Logger.Info("KF Dedicated Server files are ready", "elapsedTime", time.Since(start))
+	return nil
+}
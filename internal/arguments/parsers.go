@@ -117,23 +117,19 @@ func ParseExistingDir(a *Argument[string]) (string, error) {
 	return val, nil
 }
 
-func ParseGameMode(a *Argument[string]) (string, error) {
-	validModes := map[string]string{
-		"survival":  "KFmod.KFGameType",
-		"objective": "KFStoryGame.KFstoryGameInfo",
-		"toymaster": "KFCharPuppets.TOYGameInfo",
-	}
-
-	raw := a.RawValue()
-	val, ok := validModes[strings.ToLower(raw)]
-	if val == "" {
-		val = validModes["survival"]
-		ok = true
-	}
-	if ok {
-		return val, nil
+// ParseGameMode returns a parser resolving a game mode name to its game info class using the given lookup.
+// Unknown values are used as custom game info classes.
+func ParseGameMode(lookupClass func(name string) (string, bool)) ParseFunction[string] {
+	return func(a *Argument[string]) (string, error) {
+		raw := a.RawValue()
+		if strings.TrimSpace(raw) == "" {
+			raw = "survival"
+		}
+		if class, ok := lookupClass(raw); ok {
+			return class, nil
+		}
+		return raw, nil // Custom
 	}
-	return raw, nil // Custom
 }
 
 func ParseGameDifficulty(raw string) func(a *Argument[int]) (int, error) {
@@ -230,3 +226,14 @@ func ParseLogFileFormat(a *Argument[string]) (string, error) {
 	}
 	return val, nil
 }
+
+// ParseAutoSpecimenType returns a parser resolving the 'auto' specimen type with the given function.
+// Any other value is parsed by ParseSpecimenType.
+func ParseAutoSpecimenType(resolve func() (string, error)) ParseFunction[string] {
+	return func(a *Argument[string]) (string, error) {
+		if strings.EqualFold(strings.TrimSpace(a.RawValue()), "auto") {
+			return resolve()
+		}
+		return ParseSpecimenType(a)
+	}
+}
@@ -0,0 +1,158 @@
+package ini
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the text encoding of an ini file.
+type Encoding string
+
+const (
+	EncodingUTF8    Encoding = "utf-8"
+	EncodingUTF8BOM Encoding = "utf-8-bom"
+	EncodingUTF16LE Encoding = "utf-16le" // As written by Unreal Engine
+	EncodingUTF16BE Encoding = "utf-16be"
+)
+
+// Line endings of an ini file
+const (
+	LineEndingLF   = "\n"
+	LineEndingCRLF = "\r\n"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// ParseEncoding returns the encoding matching a name such as 'utf-16le'.
+func ParseEncoding(name string) (Encoding, error) {
+	switch enc := Encoding(strings.ToLower(strings.TrimSpace(name))); enc {
+	case EncodingUTF8, EncodingUTF8BOM, EncodingUTF16LE, EncodingUTF16BE:
+		return enc, nil
+	}
+	return "", fmt.Errorf("unknown encoding '%s', expected '%s', '%s', '%s' or '%s'",
+		name, EncodingUTF8, EncodingUTF8BOM, EncodingUTF16LE, EncodingUTF16BE)
+}
+
+// detectEncoding guesses the encoding of raw file data from its BOM.
+// Files without a BOM are UTF-16LE if their first byte is non-zero and their second one is zero,
+// as for an ASCII first character in UTF-16LE, and UTF-8 otherwise.
+func detectEncoding(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8BOM
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16BE
+	case len(data) >= 2 && data[0] != 0 && data[1] == 0:
+		return EncodingUTF16LE
+	}
+	return EncodingUTF8
+}
+
+// encodingBOM returns the BOM of an encoding.
+func encodingBOM(enc Encoding) []byte {
+	switch enc {
+	case EncodingUTF8, EncodingUTF8BOM:
+		return bomUTF8
+	case EncodingUTF16LE:
+		return bomUTF16LE
+	case EncodingUTF16BE:
+		return bomUTF16BE
+	}
+	return nil
+}
+
+// decodeContent converts raw file data in the given encoding to a UTF-8 string, dropping the BOM.
+func decodeContent(data []byte, enc Encoding) (string, error) {
+	switch enc {
+	case EncodingUTF8, EncodingUTF8BOM:
+		return string(bytes.TrimPrefix(data, bomUTF8)), nil
+	case EncodingUTF16LE, EncodingUTF16BE:
+		littleEndian := enc == EncodingUTF16LE
+		data = bytes.TrimPrefix(data, encodingBOM(enc))
+		if len(data)%2 != 0 {
+			return "", fmt.Errorf("invalid %s data: odd length", enc)
+		}
+
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if littleEndian {
+				units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+			} else {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			}
+		}
+		return string(utf16.Decode(units)), nil
+	}
+	return "", fmt.Errorf("unsupported encoding '%s'", enc)
+}
+
+// ReadFileContent reads a file and returns its content converted to UTF-8,
+// with the encoding detected the same way as Load.
+func ReadFileContent(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := decodeContent(data, detectEncoding(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode file '%s': %v", filePath, err)
+	}
+	return content, nil
+}
+
+// encodeContent converts a UTF-8 string to the given encoding.
+// UTF-16 content starts with a BOM if withBOM is set, UTF-8 content only with the utf-8-bom encoding.
+func encodeContent(content string, enc Encoding, withBOM bool) ([]byte, error) {
+	switch enc {
+	case EncodingUTF8:
+		return []byte(content), nil
+	case EncodingUTF8BOM:
+		return append(append([]byte{}, bomUTF8...), content...), nil
+	case EncodingUTF16LE, EncodingUTF16BE:
+		if !utf8.ValidString(content) {
+			return nil, fmt.Errorf("invalid UTF-8 content, cannot encode it to %s", enc)
+		}
+
+		units := utf16.Encode([]rune(content))
+		data := make([]byte, 0, 2+2*len(units))
+		if withBOM {
+			data = append(data, encodingBOM(enc)...)
+		}
+		if enc == EncodingUTF16LE {
+			for _, unit := range units {
+				data = append(data, byte(unit), byte(unit>>8))
+			}
+		} else {
+			for _, unit := range units {
+				data = append(data, byte(unit>>8), byte(unit))
+			}
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding '%s'", enc)
+}
+
+// detectLineEnding returns the line ending of the content, and whether it mixes CRLF and LF.
+// Mixed content is reported as LF.
+func detectLineEnding(content string) (lineEnding string, mixed bool) {
+	crlf := strings.Count(content, LineEndingCRLF)
+	lf := strings.Count(content, LineEndingLF) - crlf
+	switch {
+	case crlf > 0 && lf > 0:
+		return LineEndingLF, true
+	case crlf > 0:
+		return LineEndingCRLF, false
+	}
+	return LineEndingLF, false
+}
@@ -0,0 +1,48 @@
+package ini
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFileContent(t *testing.T) {
+	const content = "[A]\r\nName=Café\r\n"
+
+	// The UTF-16LE units with their bytes swapped
+	utf16BE := append([]byte{}, bomUTF16BE...)
+	le := utf16LE(content, false)
+	for i := 0; i+1 < len(le); i += 2 {
+		utf16BE = append(utf16BE, le[i+1], le[i])
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"utf-8", []byte(content), false},
+		{"utf-8 bom", append(append([]byte{}, bomUTF8...), content...), false},
+		{"utf-16le bom", utf16LE(content, true), false},
+		{"utf-16le without bom", utf16LE(content, false), false},
+		{"utf-16be bom", utf16BE, false},
+		{"truncated utf-16le", utf16LE(content, true)[:7], true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "test.ini")
+			if err := os.WriteFile(filePath, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := ReadFileContent(filePath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadFileContent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && actual != content {
+				t.Errorf("ReadFileContent() = %q, expected %q", actual, content)
+			}
+		})
+	}
+}
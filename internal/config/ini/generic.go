@@ -1,7 +1,9 @@
 package ini
 
 import (
-	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
 	"slices"
@@ -14,20 +16,36 @@ import (
 )
 
 type GenericIniFile struct {
-	name            string
-	sections        []*IniSection   // Ordered list of sections
-	sectionMap      map[string]int  // Map of section name to its index in Sections slice
-	normalizedNames map[string]bool // Tracks lowercase section names to prevent duplicates
-	Logger          *dslogger.Logger
+	name         string
+	sections     []*IniSection  // Ordered list of sections
+	sectionMap   map[string]int // Map of lowercase section name to its index in Sections slice
+	trailing     []string       // Comment and blank lines of a file without sections
+	finalNewline bool           // Whether the file ends with a newline
+	parseMode    ParseMode
+	encoding     Encoding
+	lineEnding   string
+	bom          bool           // Whether UTF-16 content is written with a BOM
+	explicitEnc  bool           // The encoding is set by the caller rather than detected on load
+	explicitEOL  bool           // The line ending is set by the caller rather than detected on load
+	warnings     []ParseWarning // Problems found by the last Load
+	base         iniState       // Values as loaded, to merge the changes made on disk before saving
+	mergeBase    MergeBase      // Digest of the values last written, set by the caller
+	loadedPath   string
+	loadedHash   [sha256.Size]byte
+	conflicts    []MergeConflict // Conflicts found by the last Save
+	Logger       *dslogger.Logger
 }
 
 func NewGenericIniFile(name string) *GenericIniFile {
 	return &GenericIniFile{
-		name:            name,
-		sections:        []*IniSection{},
-		sectionMap:      make(map[string]int),
-		normalizedNames: make(map[string]bool),
-		Logger:          log.Logger.WithService(name),
+		name:         name,
+		sections:     []*IniSection{},
+		sectionMap:   make(map[string]int),
+		finalNewline: true,
+		encoding:     EncodingUTF8,
+		bom:          true,
+		lineEnding:   LineEndingLF,
+		Logger:       log.Logger.WithService(name),
 	}
 }
 
@@ -35,16 +53,44 @@ func (f *GenericIniFile) Name() string {
 	return f.name
 }
 
+func (f *GenericIniFile) Encoding() Encoding {
+	return f.encoding
+}
+
+// SetEncoding sets the encoding used to load and save the file instead of the detected one.
+// UTF-16 files are written with a BOM, unless the file loaded afterwards had none.
+func (f *GenericIniFile) SetEncoding(enc Encoding) {
+	f.encoding = enc
+	f.bom = true
+	f.explicitEnc = true
+}
+
+func (f *GenericIniFile) LineEnding() string {
+	return f.lineEnding
+}
+
+// SetLineEnding sets the line ending used to save the file instead of the detected one.
+func (f *GenericIniFile) SetLineEnding(lineEnding string) {
+	f.lineEnding = lineEnding
+	f.explicitEOL = true
+}
+
+func (f *GenericIniFile) SetParseMode(mode ParseMode) {
+	f.parseMode = mode
+}
+
+// Warnings returns the problems found by the last Load.
+func (f *GenericIniFile) Warnings() []ParseWarning {
+	return f.warnings
+}
+
 func (f *GenericIniFile) Sections() []*IniSection {
 	return f.sections
 }
 
+// GetSection returns a section by name, ignoring the case.
 func (f *GenericIniFile) GetSection(name string) *IniSection {
-	if _, exists := f.normalizedNames[strings.ToLower(name)]; !exists {
-		return nil
-	}
-
-	if idx, exists := f.sectionMap[name]; exists {
+	if idx, exists := f.sectionMap[strings.ToLower(name)]; exists {
 		return f.sections[idx]
 	}
 	return nil
@@ -52,14 +98,17 @@ func (f *GenericIniFile) GetSection(name string) *IniSection {
 
 func (f *GenericIniFile) AddSection(name string) (*IniSection, error) {
 	lowerName := strings.ToLower(name)
-	if _, exists := f.normalizedNames[strings.ToLower(name)]; exists {
+	if _, exists := f.sectionMap[lowerName]; exists {
 		return nil, fmt.Errorf("duplicate section found: %s", name)
 	}
 
 	section := NewIniSection(name)
+	if len(f.sections) > 0 {
+		// Separate the new section from the previous one
+		section.before = []string{""}
+	}
 	f.sections = append(f.sections, section)
-	f.sectionMap[name] = len(f.sections) - 1
-	f.normalizedNames[lowerName] = true
+	f.sectionMap[lowerName] = len(f.sections) - 1
 
 	f.Logger.Debug("Adding new section",
 		"function", "AddSection", "section", name, "totalSections", len(f.sections))
@@ -75,7 +124,6 @@ func (f *GenericIniFile) DeleteSection(name string) bool {
 
 	f.sections = slices.Delete(f.sections, idx, idx+1)
 	delete(f.sectionMap, lowerName)
-	delete(f.normalizedNames, lowerName)
 
 	// Rebuild the map
 	for i, section := range f.sections {
@@ -151,6 +199,100 @@ func (f *GenericIniFile) SetKeyFloat(section string, key string, value float64,
 	return f.setKeyValue(section, key, value, unique)
 }
 
+// GetKeyString returns the unquoted value of a key.
+func (f *GenericIniFile) GetKeyString(section string, key string, defvalue string) string {
+	if sect := f.GetSection(section); sect != nil {
+		if value, exists := sect.GetKey(key); exists {
+			return Unquote(value)
+		}
+	}
+	return defvalue
+}
+
+// SetKeyString sets the value of a key as a quoted string.
+func (f *GenericIniFile) SetKeyString(section string, key string, value string, unique bool) bool {
+	return f.setKeyValue(section, key, Quote(value), unique)
+}
+
+// GetKeyIndexed returns the value of an indexed key such as "Key[3]".
+func (f *GenericIniFile) GetKeyIndexed(section string, key string, index int, defvalue string) string {
+	if sect := f.GetSection(section); sect != nil {
+		if value, exists := sect.GetIndexedKey(key, index); exists {
+			return value
+		}
+	}
+	return defvalue
+}
+
+// GetKeysIndexed returns the values of an indexed key by index.
+func (f *GenericIniFile) GetKeysIndexed(section string, key string) map[int]string {
+	if sect := f.GetSection(section); sect != nil {
+		return sect.GetIndexedKeys(key)
+	}
+	return nil
+}
+
+func (f *GenericIniFile) SetKeyIndexed(section string, key string, index int, value any) bool {
+	sect, err := f.getOrAddSection(section)
+	if err != nil {
+		return false
+	}
+
+	val := cast.ToString(value)
+	sect.SetIndexedKey(key, index, val)
+
+	f.Logger.Debug("Setting indexed key",
+		"function", "SetKeyIndexed", "section", section, "key", key, "index", index, "value", val)
+
+	current, exists := sect.GetIndexedKey(key, index)
+	return exists && current == val
+}
+
+// GetKeyStructs parses the values of a key, in order of appearance, as structs.
+func (f *GenericIniFile) GetKeyStructs(section string, key string) ([]*StructValue, error) {
+	var structs []*StructValue
+	for _, value := range f.GetKeys(section, key) {
+		sv, err := ParseStruct(value)
+		if err != nil {
+			return nil, fmt.Errorf("[%s].%s: %w", section, key, err)
+		}
+		structs = append(structs, sv)
+	}
+	return structs, nil
+}
+
+// SetKeyStructs replaces the values of a key with the given structs.
+func (f *GenericIniFile) SetKeyStructs(section string, key string, values []*StructValue) bool {
+	sect, err := f.getOrAddSection(section)
+	if err != nil {
+		return false
+	}
+
+	strValues := make([]string, len(values))
+	for i, value := range values {
+		strValues[i] = value.String()
+	}
+	sect.ReplaceKeys(key, strValues)
+
+	f.Logger.Debug("Setting struct keys",
+		"function", "SetKeyStructs", "section", section, "key", key, "count", len(values))
+	return len(sect.GetKeys(key)) == len(values)
+}
+
+// SetKeys replaces the values of a multi-value key.
+// The existing keys are updated in place, extra values are appended and surplus keys are deleted.
+func (f *GenericIniFile) SetKeys(section string, key string, values []string) bool {
+	sect, err := f.getOrAddSection(section)
+	if err != nil {
+		return false
+	}
+	sect.ReplaceKeys(key, values)
+
+	f.Logger.Debug("Setting keys",
+		"function", "SetKeys", "section", section, "key", key, "values", values)
+	return slices.Equal(sect.GetKeys(key), values)
+}
+
 func (f *GenericIniFile) DeleteKey(section string, key string) bool {
 	if !f.HasKey(section, key) {
 		return false
@@ -206,57 +348,137 @@ func (f *GenericIniFile) Load(filePath string) error {
 	f.Logger.Debug("Loading ini file",
 		"function", "Load", "file", filePath)
 
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to open file '%s': %v", filePath, err)
 	}
-	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	if !f.explicitEnc {
+		f.encoding = detectEncoding(data)
+	}
+	f.bom = len(data) == 0 || bytes.HasPrefix(data, encodingBOM(f.encoding))
+	content, err := decodeContent(data, f.encoding)
+	if err != nil {
+		return fmt.Errorf("failed to decode file '%s': %v", filePath, err)
+	}
+
+	// Lines are handled without their line ending, which is restored on save.
+	// The CRs of a file mixing line endings stay in its lines, so they're written back as they were
+	lineEnding, mixed := detectLineEnding(content)
+	if !f.explicitEOL {
+		f.lineEnding = lineEnding
+	}
+	if lineEnding == LineEndingCRLF || (mixed && f.explicitEOL) {
+		content = strings.ReplaceAll(content, LineEndingCRLF, LineEndingLF)
+	}
+
+	f.Logger.Debug("Ini file format detected",
+		"function", "Load", "file", filePath, "encoding", f.encoding, "bom", f.bom,
+		"crlf", lineEnding == LineEndingCRLF, "mixedLineEndings", mixed)
+
+	f.finalNewline = content == "" || strings.HasSuffix(content, "\n")
+	f.warnings = nil
+
 	var currentSection *IniSection
+	var pending []string // Comment, blank and unparseable lines waiting for the next section or key
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	for i, rawLine := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		line := strings.TrimSpace(rawLine)
 		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			pending = append(pending, rawLine)
 			continue
 		}
 
 		// Check for section header
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
 			sectionName := strings.TrimSpace(line[1 : len(line)-1])
+			if existing := f.GetSection(sectionName); existing != nil {
+				// The keys are merged into the first section, the comments go to the next key
+				f.addWarning(filePath, i+1, line, "duplicate section merged")
+				currentSection = existing
+				continue
+			}
 			if currentSection, err = f.AddSection(sectionName); err != nil {
 				return err
 			}
-		} else if currentSection != nil {
-			// Parse key/value pair
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) != 2 {
-				return fmt.Errorf("invalid line in file '%s': %s", filePath, line)
-			}
+			currentSection.before, currentSection.header = pending, rawLine
+			pending = nil
+			continue
+		}
 
-			key := strings.TrimSpace(parts[0])
-			val := strings.TrimSpace(parts[1])
-			currentSection.AddKey(key, val)
+		// Parse key/value pair
+		name, val, layout, ok := parseKeyLine(rawLine)
+		if !ok || name == "" {
+			f.addWarning(filePath, i+1, line, "invalid line kept as is")
+			pending = append(pending, rawLine)
+			continue
+		}
 
-			f.Logger.Debug("Parsing key",
-				"function", "Load", "section", currentSection.Name(), "key", key, "value", val)
-		} else {
-			return fmt.Errorf("key-value pair found outside of a section in file '%s': %s", filePath, line)
+		if currentSection == nil {
+			f.addWarning(filePath, i+1, line, "key found outside of a section")
+			if currentSection, err = f.AddSection(""); err != nil {
+				return err
+			}
 		}
+
+		key := currentSection.addKey(name, val)
+		key.before, key.layout = pending, layout
+		pending = nil
+
+		f.Logger.Debug("Parsing key",
+			"function", "Load", "section", currentSection.Name(), "key", name, "value", val)
+	}
+	// The lines following the last key belong to the last section, so the sections added later go after them.
+	// An empty file has no line at all
+	f.trailing = nil
+	if len(f.sections) > 0 {
+		f.sections[len(f.sections)-1].after = pending
+	} else if content != "" {
+		f.trailing = pending
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading file '%s': %v", filePath, err)
+	if f.parseMode == ParseStrict && len(f.warnings) > 0 {
+		errs := make([]error, len(f.warnings))
+		for i, warning := range f.warnings {
+			errs[i] = warning
+		}
+		return fmt.Errorf("invalid ini file '%s':\n%w", filePath, errors.Join(errs...))
 	}
 
+	f.base, f.loadedPath, f.loadedHash = f.state(), filePath, sha256.Sum256(data)
+
 	f.Logger.Debug("Ini file successfully loaded",
 		"function", "Save", "file", filePath)
 	return nil
 }
 
+func (f *GenericIniFile) addWarning(filePath string, line int, content string, message string) {
+	warning := ParseWarning{File: filePath, Line: line, Content: content, Message: message}
+	f.warnings = append(f.warnings, warning)
+
+	if f.parseMode == ParseLenient {
+		f.Logger.Warn("Ini file parsing issue",
+			"function", "Load", "file", filePath, "line", line, "content", content, "issue", message)
+	}
+}
+
 func (f *GenericIniFile) Save(filePath string) error {
 	tempFilePath := filePath + ".tmp"
 
+	unlock, err := lockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// Keep the changes made on disk since the file was loaded
+	f.conflicts = nil
+	if (f.base != nil && filePath == f.loadedPath) || f.mergeBase != nil {
+		if err := f.mergeDiskChanges(filePath); err != nil {
+			return err
+		}
+	}
+
 	f.Logger.Debug("Creating temp ini file",
 		"function", "Save", "file", tempFilePath)
 
@@ -271,14 +493,36 @@ func (f *GenericIniFile) Save(filePath string) error {
 		}
 	}()
 
-	writer := bufio.NewWriter(file)
+	// The content is encoded once complete
+	writer := &strings.Builder{}
+	lines := 0
+	writeLines := func(newLines ...string) error {
+		for _, line := range newLines {
+			// Lines are separated rather than terminated to keep a missing final newline
+			if lines > 0 {
+				if _, err := writer.WriteString(f.lineEnding); err != nil {
+					return err
+				}
+			}
+			if _, err := writer.WriteString(line); err != nil {
+				return err
+			}
+			lines++
+		}
+		return nil
+	}
+
 	for _, section := range f.sections {
+		if err := writeLines(section.before...); err != nil {
+			return fmt.Errorf("failed to write the comments of section '%s': %v", section.Name(), err)
+		}
+
 		// Write section header
 		if section.Name() != "" {
 			f.Logger.Debug("Writing section",
 				"function", "Save", "section", section.Name())
 
-			if _, err := writer.WriteString(fmt.Sprintf("[%s]\n", section.Name())); err != nil {
+			if err := writeLines(section.HeaderLine()); err != nil {
 				return fmt.Errorf("failed to write section header for '%s': %v", section.Name(), err)
 			}
 		}
@@ -288,19 +532,34 @@ func (f *GenericIniFile) Save(filePath string) error {
 			f.Logger.Debug("Writing key",
 				"function", "Save", "section", section.Name(), "key", key.Name, "value", key.Value)
 
-			if _, err := writer.WriteString(fmt.Sprintf("%s=%s\n", key.Name, key.Value)); err != nil {
+			if err := writeLines(key.before...); err != nil {
+				return fmt.Errorf("failed to write the comments of key '%s' in section '%s': %v", key.Name, section.Name(), err)
+			}
+			if err := writeLines(key.Line()); err != nil {
 				return fmt.Errorf("failed to write key '%s' in section '%s': %v", key.Name, section.Name(), err)
 			}
 		}
 
-		// Add a newline between sections
-		if _, err := writer.WriteString("\n"); err != nil {
-			return fmt.Errorf("failed to write newline between sections: %v", err)
+		if err := writeLines(section.after...); err != nil {
+			return fmt.Errorf("failed to write the trailing comments of section '%s': %v", section.Name(), err)
+		}
+	}
+
+	if err := writeLines(f.trailing...); err != nil {
+		return fmt.Errorf("failed to write the trailing comments: %v", err)
+	}
+	if f.finalNewline && lines > 0 {
+		if _, err := writer.WriteString(f.lineEnding); err != nil {
+			return fmt.Errorf("failed to write the final newline: %v", err)
 		}
 	}
 
-	if err = writer.Flush(); err != nil {
-		return fmt.Errorf("failed to flush file '%s': %v", tempFilePath, err)
+	data, err := encodeContent(writer.String(), f.encoding, f.bom)
+	if err != nil {
+		return fmt.Errorf("failed to encode file '%s': %v", tempFilePath, err)
+	}
+	if _, err = file.Write(data); err != nil {
+		return fmt.Errorf("failed to write file '%s': %v", tempFilePath, err)
 	}
 
 	if err = file.Sync(); err != nil {
@@ -313,25 +572,42 @@ func (f *GenericIniFile) Save(filePath string) error {
 	if err := os.Rename(tempFilePath, filePath); err != nil {
 		return fmt.Errorf("failed to rename file '%s' to '%s': %v", tempFilePath, filePath, err)
 	}
+	f.base, f.loadedPath, f.loadedHash = f.state(), filePath, sha256.Sum256(data)
+	f.mergeBase = nil
 
 	f.Logger.Debug("Ini file successfully saved",
 		"function", "Save", "sourcefile", tempFilePath, "destFile", filePath)
 	return nil
 }
 
-func (f *GenericIniFile) setKeyValue(section string, key string, value any, unique bool) bool {
+func (f *GenericIniFile) getOrAddSection(section string) (*IniSection, error) {
 	sect := f.GetSection(section)
 
 	// Add the section if it doesn't exists
 	if sect == nil {
 		var err error
 
+		// Keys of the unnamed section would be saved under the previous section
+		if section == "" && len(f.sections) > 0 {
+			err = fmt.Errorf("cannot add keys outside of a section to a file with sections")
+			f.Logger.Error("Failed to add new section", "section", section, "error", err)
+			return nil, err
+		}
+
 		sect, err = f.AddSection(section)
 		if err != nil {
 			f.Logger.Error("Failed to add new section", "section", section, "error", err)
-			return false
+			return nil, err
 		}
 	}
+	return sect, nil
+}
+
+func (f *GenericIniFile) setKeyValue(section string, key string, value any, unique bool) bool {
+	sect, err := f.getOrAddSection(section)
+	if err != nil {
+		return false
+	}
 
 	val := cast.ToString(value)
 	isSet := false
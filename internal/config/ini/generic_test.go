@@ -0,0 +1,124 @@
+package ini
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+)
+
+// utf16LE encodes a string to UTF-16LE, with or without a BOM.
+func utf16LE(content string, withBOM bool) []byte {
+	var data []byte
+	if withBOM {
+		data = append(data, bomUTF16LE...)
+	}
+	for _, unit := range utf16.Encode([]rune(content)) {
+		data = append(data, byte(unit), byte(unit>>8))
+	}
+	return data
+}
+
+// saveTestIniFile loads raw data, applies a change and returns the saved data.
+func saveTestIniFile(t *testing.T, data []byte, change func(file *GenericIniFile)) []byte {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "test.ini")
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := NewGenericIniFile("test")
+	if err := file.Load(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if change != nil {
+		change(file)
+	}
+	if err := file.Save(filePath); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte("")},
+		{"comments and spacing", []byte("; header\n\n[A]\n  K = 1 \n; about J\nJ=2\n\n# about B\n[B]  \nX=\n; about B\n")},
+		{"no final newline", []byte("[A]\nK=1")},
+		{"duplicate keys", []byte("[A]\nK=1\nK=2\nK[0]=3\n")},
+		{"comments only", []byte("; nothing\n\n")},
+		{"crlf", []byte("[A]\r\nK=1\r\n\r\n[B]\r\nX=2\r\n")},
+		{"mixed line endings", []byte("[A]\r\nK=1\nJ=2\r\n\n; about A\r\n")},
+		{"utf-8 bom", append(append([]byte{}, bomUTF8...), "[A]\r\nName=Café\r\n"...)},
+		{"utf-16le bom", utf16LE("[A]\r\nName=Café\r\n", true)},
+		{"utf-16le without bom", utf16LE("[A]\r\nName=Café\r\n", false)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if saved := saveTestIniFile(t, tt.data, nil); !bytes.Equal(saved, tt.data) {
+				t.Errorf("round trip changed the file:\n%q\nexpected:\n%q", saved, tt.data)
+			}
+		})
+	}
+}
+
+func TestSaveKeepsLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		change   func(file *GenericIniFile)
+		expected string
+	}{
+		{
+			name: "new section after the trailing comments",
+			data: "[A]\nK=1\n; about A\n",
+			change: func(file *GenericIniFile) {
+				file.SetKey("B", "X", "1", true)
+			},
+			expected: "[A]\nK=1\n; about A\n\n[B]\nX=1\n",
+		},
+		{
+			name: "new key before the trailing comments",
+			data: "[A]\nK=1\n; about A\n",
+			change: func(file *GenericIniFile) {
+				file.SetKey("A", "J", "2", true)
+			},
+			expected: "[A]\nK=1\nJ=2\n; about A\n",
+		},
+		{
+			name: "changed value keeps its spacing",
+			data: "[A]\n  K = 1 \n",
+			change: func(file *GenericIniFile) {
+				file.SetKey("A", "K", "2", true)
+			},
+			expected: "[A]\n  K = 2 \n",
+		},
+		{
+			name: "new key uses the crlf line ending",
+			data: "[A]\r\nK=1\r\n",
+			change: func(file *GenericIniFile) {
+				file.SetKey("A", "J", "2", true)
+			},
+			expected: "[A]\r\nK=1\r\nJ=2\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if saved := saveTestIniFile(t, []byte(tt.data), tt.change); string(saved) != tt.expected {
+				t.Errorf("saved:\n%q\nexpected:\n%q", saved, tt.expected)
+			}
+		})
+	}
+}
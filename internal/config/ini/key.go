@@ -1,7 +1,64 @@
 package ini
 
+import (
+	"strings"
+	"unicode"
+)
+
 type IniKey struct {
-	Name  string
-	Value string
-	Index int
+	Name   string
+	Value  string
+	Index  int
+	before []string  // Comment and blank lines preceding the key
+	layout keyLayout // Original spacing around the name and the value
+}
+
+// keyLayout holds the whitespace surrounding a key line parts.
+type keyLayout struct {
+	indent    string
+	sepBefore string
+	sepAfter  string
+	trailing  string
+}
+
+// parseKeyLine splits a raw "name=value" line into its trimmed name and value and their layout.
+func parseKeyLine(line string) (name string, value string, layout keyLayout, ok bool) {
+	left, right, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", keyLayout{}, false
+	}
+
+	name = strings.TrimLeftFunc(left, unicode.IsSpace)
+	layout.indent = left[:len(left)-len(name)]
+	name = strings.TrimRightFunc(name, unicode.IsSpace)
+	layout.sepBefore = left[len(layout.indent)+len(name):]
+
+	rest := strings.TrimLeftFunc(right, unicode.IsSpace)
+	value = strings.TrimRightFunc(rest, unicode.IsSpace)
+	if value == "" {
+		layout.trailing = right
+	} else {
+		layout.sepAfter = right[:len(right)-len(rest)]
+		layout.trailing = rest[len(value):]
+	}
+	return name, value, layout, true
+}
+
+// HasName reports whether the key has the given name, ignoring the case.
+func (k *IniKey) HasName(name string) bool {
+	return strings.EqualFold(k.Name, name)
+}
+
+// Struct parses the key value as a struct.
+func (k *IniKey) Struct() (*StructValue, error) {
+	return ParseStruct(k.Value)
+}
+
+func (k *IniKey) SetStruct(value *StructValue) {
+	k.Value = value.String()
+}
+
+// Line returns the key line, keeping the original layout.
+func (k *IniKey) Line() string {
+	return k.layout.indent + k.Name + k.layout.sepBefore + "=" + k.layout.sepAfter + k.Value + k.layout.trailing
 }
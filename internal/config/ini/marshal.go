@@ -0,0 +1,267 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// Struct fields are bound to ini keys through tags such as:
+//
+//	MaxPlayers int      `ini:"Engine.GameInfo,MaxPlayers" default:"6"`
+//	ServerName string   `ini:"Engine.GameReplicationInfo,ServerName,quoted"`
+//	Actors     []string `ini:"Engine.GameEngine,ServerActors"`
+//
+// Slices map to repeated keys. The 'quoted' option reads and writes the values as quoted strings.
+// Untagged struct and struct pointer fields are bound recursively.
+
+var structValueType = reflect.TypeOf((*StructValue)(nil))
+
+type iniTag struct {
+	section string
+	key     string
+	quoted  bool
+}
+
+func parseIniTag(tag string) (iniTag, error) {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+		return iniTag{}, fmt.Errorf("invalid ini tag '%s', expected 'Section,Key'", tag)
+	}
+
+	t := iniTag{section: strings.TrimSpace(parts[0]), key: strings.TrimSpace(parts[1])}
+	for _, option := range parts[2:] {
+		switch strings.TrimSpace(option) {
+		case "quoted":
+			t.quoted = true
+		default:
+			return iniTag{}, fmt.Errorf("unknown option '%s' in ini tag '%s'", option, tag)
+		}
+	}
+	return t, nil
+}
+
+// Unmarshal reads the tagged fields of the struct pointed to by v from the ini file.
+// Missing and empty keys take the value of the field 'default' tag, if any.
+// Missing keys without a default leave the field untouched. Nil struct pointers are allocated.
+func Unmarshal(file *GenericIniFile, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal target must be a non-nil struct pointer, got %T", v)
+	}
+	return walkIniFields(rv.Elem(), true, func(field reflect.Value, tag iniTag, defvalue *string) error {
+		values := file.GetKeys(tag.section, tag.key)
+		if defvalue != nil && (values == nil || (len(values) == 1 && values[0] == "")) {
+			values = []string{*defvalue}
+			if field.Kind() == reflect.Slice && field.Type().Elem() != structValueType {
+				values = strings.Split(*defvalue, ",")
+			}
+		}
+		if values == nil {
+			return nil
+		}
+
+		if field.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+			for i, value := range values {
+				if err := setIniFieldValue(slice.Index(i), value, tag.quoted); err != nil {
+					return fmt.Errorf("[%s].%s: %w", tag.section, tag.key, err)
+				}
+			}
+			field.Set(slice)
+			return nil
+		}
+
+		if err := setIniFieldValue(field, values[0], tag.quoted); err != nil {
+			return fmt.Errorf("[%s].%s: %w", tag.section, tag.key, err)
+		}
+		return nil
+	})
+}
+
+// Marshal writes the tagged fields of the struct pointed to by v to the ini file.
+// Slices replace all the values of their repeated key. Keys already holding the field value
+// are left untouched, so their formatting is kept. Nil struct pointers are skipped.
+func Marshal(v any, file *GenericIniFile) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("marshal source must be a struct or a struct pointer, got %T", v)
+	}
+	return walkIniFields(rv, false, func(field reflect.Value, tag iniTag, _ *string) error {
+		if field.Type() == structValueType && field.IsNil() {
+			return nil
+		}
+		if hasIniFieldValue(field, file.GetKeys(tag.section, tag.key), tag.quoted) {
+			return nil
+		}
+
+		if field.Kind() == reflect.Slice {
+			values := make([]string, field.Len())
+			for i := range values {
+				values[i] = iniFieldValue(field.Index(i), tag.quoted)
+			}
+			if !file.SetKeys(tag.section, tag.key, values) {
+				return fmt.Errorf("[%s].%s: failed to set the values: %v", tag.section, tag.key, values)
+			}
+			return nil
+		}
+
+		value := iniFieldValue(field, tag.quoted)
+		if !file.SetKey(tag.section, tag.key, value, true) {
+			return fmt.Errorf("[%s].%s: failed to set the value: %s", tag.section, tag.key, value)
+		}
+		return nil
+	})
+}
+
+// walkIniFields calls fn for every tagged field of a struct, recursing into the untagged struct and struct pointer fields.
+// Nil struct pointers are allocated if alloc is set, or skipped otherwise.
+func walkIniFields(rv reflect.Value, alloc bool, fn func(field reflect.Value, tag iniTag, defvalue *string) error) error {
+	var errs []error
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		structField := rt.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+		field := rv.Field(i)
+
+		tagStr, tagged := structField.Tag.Lookup("ini")
+		if !tagged {
+			if field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.Struct {
+				if field.IsNil() {
+					if !alloc {
+						continue
+					}
+					field.Set(reflect.New(field.Type().Elem()))
+				}
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct {
+				if err := walkIniFields(field, alloc, fn); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		if tagStr == "-" {
+			continue
+		}
+
+		tag, err := parseIniTag(tagStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", structField.Name, err))
+			continue
+		}
+
+		var defvalue *string
+		if def, ok := structField.Tag.Lookup("default"); ok {
+			defvalue = &def
+		}
+		if err := fn(field, tag, defvalue); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setIniFieldValue(field reflect.Value, value string, quoted bool) error {
+	if field.Type() == structValueType {
+		sv, err := ParseStruct(value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(sv))
+		return nil
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch field.Kind() {
+	case reflect.String:
+		if quoted {
+			value = Unquote(value)
+		}
+		field.SetString(value)
+		return nil
+	case reflect.Bool:
+		result, err = cast.ToBoolE(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		result, err = cast.ToInt64E(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		result, err = cast.ToUint64E(value)
+	case reflect.Float32, reflect.Float64:
+		result, err = cast.ToFloat64E(value)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value '%s'", field.Kind(), value)
+	}
+
+	field.Set(reflect.ValueOf(result).Convert(field.Type()))
+	return nil
+}
+
+// hasIniFieldValue reports whether the values of a key parse to the value of a field.
+func hasIniFieldValue(field reflect.Value, values []string, quoted bool) bool {
+	if field.Kind() == reflect.Slice {
+		if len(values) != field.Len() {
+			return false
+		}
+	} else if len(values) != 1 {
+		return false
+	}
+
+	for i, value := range values {
+		expected := field
+		if field.Kind() == reflect.Slice {
+			expected = field.Index(i)
+		}
+
+		parsed := reflect.New(expected.Type()).Elem()
+		if err := setIniFieldValue(parsed, value, quoted); err != nil {
+			return false
+		}
+		if !reflect.DeepEqual(parsed.Interface(), expected.Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+func iniFieldValue(field reflect.Value, quoted bool) string {
+	if field.Type() == structValueType {
+		if field.IsNil() {
+			return "()"
+		}
+		return field.Interface().(*StructValue).String()
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if quoted {
+			return Quote(field.String())
+		}
+		return field.String()
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, field.Type().Bits())
+	}
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,248 @@
+package ini
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/K4rian/kfdsl/internal/log"
+)
+
+func TestMain(m *testing.M) {
+	log.Init("error", "", "text", 1, 1, 1, false)
+	os.Exit(m.Run())
+}
+
+// loadTestIniFile loads an ini file from its content.
+func loadTestIniFile(t *testing.T, content string) *GenericIniFile {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "test.ini")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file := NewGenericIniFile("test")
+	if err := file.Load(filePath); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func mustParseStruct(t *testing.T, value string) *StructValue {
+	t.Helper()
+
+	sv, err := ParseStruct(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sv
+}
+
+type marshalTestInner struct {
+	Level int `ini:"Inner,Level"`
+}
+
+type marshalTestStruct struct {
+	Enabled  bool         `ini:"Main,bEnabled"`
+	Players  int          `ini:"Main,MaxPlayers"`
+	Rate     float64      `ini:"Main,Rate"`
+	Name     string       `ini:"Main,Name"`
+	Title    string       `ini:"Main,Title,quoted"`
+	Actors   []string     `ini:"Main,Actor"`
+	Defaults int          `ini:"Main,Defaults" default:"6"`
+	List     []string     `ini:"Main,List" default:"a,b"`
+	Entry    *StructValue `ini:"Main,Entry"`
+	Inner    *marshalTestInner
+	Skipped  string `ini:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected marshalTestStruct
+		wantErr  bool
+	}{
+		{
+			name:     "bool",
+			content:  "[Main]\nbEnabled=True\n",
+			expected: marshalTestStruct{Enabled: true},
+		},
+		{
+			name:     "int",
+			content:  "[Main]\nMaxPlayers=12\n",
+			expected: marshalTestStruct{Players: 12},
+		},
+		{
+			name:     "float",
+			content:  "[Main]\nRate=0.500000\n",
+			expected: marshalTestStruct{Rate: 0.5},
+		},
+		{
+			name:     "string",
+			content:  "[Main]\nName=KF Server\nTitle=\"Hello, World\"\n",
+			expected: marshalTestStruct{Name: "KF Server", Title: "Hello, World"},
+		},
+		{
+			name:     "slice",
+			content:  "[Main]\nActor=A.B\nActor=C.D\n",
+			expected: marshalTestStruct{Actors: []string{"A.B", "C.D"}},
+		},
+		{
+			name:     "default on missing keys",
+			content:  "[Main]\n",
+			expected: marshalTestStruct{Defaults: 6, List: []string{"a", "b"}},
+		},
+		{
+			name:     "default on empty keys",
+			content:  "[Main]\nDefaults=\nList=\n",
+			expected: marshalTestStruct{Defaults: 6, List: []string{"a", "b"}},
+		},
+		{
+			name:     "default ignored on set keys",
+			content:  "[Main]\nDefaults=8\nList=c\n",
+			expected: marshalTestStruct{Defaults: 8, List: []string{"c"}},
+		},
+		{
+			name:     "struct value",
+			content:  "[Main]\nEntry=(Name=\"KF-Farm\",Count=2)\n",
+			expected: marshalTestStruct{Entry: mustParseStruct(t, "(Name=\"KF-Farm\",Count=2)")},
+		},
+		{
+			name:     "struct pointer",
+			content:  "[Inner]\nLevel=3\n",
+			expected: marshalTestStruct{Inner: &marshalTestInner{Level: 3}},
+		},
+		{
+			name:    "invalid int",
+			content: "[Main]\nMaxPlayers=many\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := loadTestIniFile(t, tt.content)
+
+			var actual marshalTestStruct
+			err := Unmarshal(file, &actual)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			// Only compare the fields set by the test case, the struct pointer is always allocated
+			if tt.expected.Inner == nil {
+				tt.expected.Inner = &marshalTestInner{}
+			}
+			if tt.expected.Defaults == 0 {
+				tt.expected.Defaults = 6
+			}
+			if tt.expected.List == nil {
+				tt.expected.List = []string{"a", "b"}
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Unmarshal() = %+v, expected %+v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUnmarshalKeepsMissingFields(t *testing.T) {
+	file := loadTestIniFile(t, "[Main]\n")
+
+	actual := marshalTestStruct{Name: "kept", Actors: []string{"kept"}}
+	if err := Unmarshal(file, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if actual.Name != "kept" || !reflect.DeepEqual(actual.Actors, []string{"kept"}) {
+		t.Errorf("Unmarshal() changed the fields of missing keys: %+v", actual)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		value    marshalTestStruct
+		expected string
+	}{
+		{
+			name:     "scalars",
+			content:  "[Main]\n",
+			value:    marshalTestStruct{Enabled: true, Players: 12, Rate: 0.5, Name: "KF Server", Title: "Hello, World", Defaults: 6},
+			expected: "[Main]\nbEnabled=true\nMaxPlayers=12\nRate=0.5\nName=KF Server\nTitle=\"Hello, World\"\nDefaults=6\n",
+		},
+		{
+			name:     "slice replaces the repeated key",
+			content:  "[Main]\nActor=A.B\nActor=C.D\nActor=E.F\n",
+			value:    marshalTestStruct{Actors: []string{"C.D", "G.H"}},
+			expected: "[Main]\nActor=C.D\nActor=G.H\nbEnabled=false\nMaxPlayers=0\nRate=0\nName=\nTitle=\"\"\nDefaults=0\n",
+		},
+		{
+			name:     "equal values keep their formatting",
+			content:  "[Main]\nbEnabled=True\nMaxPlayers=12\nRate=0.500000\nName=KF Server\nTitle=\"Hello\"\nDefaults=6\n",
+			value:    marshalTestStruct{Enabled: true, Players: 12, Rate: 0.5, Name: "KF Server", Title: "Hello", Defaults: 6},
+			expected: "[Main]\nbEnabled=True\nMaxPlayers=12\nRate=0.500000\nName=KF Server\nTitle=\"Hello\"\nDefaults=6\n",
+		},
+		{
+			name:     "struct pointer",
+			content:  "[Main]\nbEnabled=false\nMaxPlayers=0\nRate=0\nName=\nTitle=\"\"\nDefaults=0\n",
+			value:    marshalTestStruct{Inner: &marshalTestInner{Level: 3}},
+			expected: "[Main]\nbEnabled=false\nMaxPlayers=0\nRate=0\nName=\nTitle=\"\"\nDefaults=0\n\n[Inner]\nLevel=3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := loadTestIniFile(t, tt.content)
+			if err := Marshal(&tt.value, file); err != nil {
+				t.Fatal(err)
+			}
+
+			filePath := filepath.Join(t.TempDir(), "out.ini")
+			if err := file.Save(filePath); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("Marshal() wrote:\n%s\nexpected:\n%s", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	expected := marshalTestStruct{
+		Enabled:  true,
+		Players:  6,
+		Rate:     0.25,
+		Name:     "KF Server",
+		Title:    "Hello, \"World\"",
+		Actors:   []string{"A.B", "C.D"},
+		Defaults: 4,
+		List:     []string{"x"},
+		Entry:    mustParseStruct(t, "(Name=\"KF-Farm\")"),
+		Inner:    &marshalTestInner{Level: 2},
+	}
+
+	file := NewGenericIniFile("test")
+	if err := Marshal(&expected, file); err != nil {
+		t.Fatal(err)
+	}
+
+	var actual marshalTestStruct
+	if err := Unmarshal(file, &actual); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("round trip = %+v, expected %+v", actual, expected)
+	}
+}
@@ -0,0 +1,217 @@
+package ini
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// MergeConflict is a key changed both on disk and in memory since the file was last written.
+// The in-memory values are kept.
+type MergeConflict struct {
+	Section string
+	Key     string
+	Current []string
+	Desired []string
+}
+
+func (c MergeConflict) String() string {
+	return fmt.Sprintf("[%s].%s: changed on disk to %q, kept %q", c.Section, c.Key, c.Current, c.Desired)
+}
+
+// MergeBase holds a digest of the values of an ini file by lowercase section and key names.
+// It can be persisted without disclosing the values.
+type MergeBase map[string]map[string]string
+
+func (b MergeBase) digest(section string, key string) string {
+	return b[strings.ToLower(section)][strings.ToLower(key)]
+}
+
+// valuesDigest returns the digest of the values of a key, or an empty string if the key doesn't exist.
+func valuesDigest(values []string) string {
+	if values == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(strings.Join(values, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// iniState holds the values of an ini file by lowercase section and key names.
+type iniState map[string]map[string][]string
+
+func (s iniState) values(section string, key string) []string {
+	return s[strings.ToLower(section)][strings.ToLower(key)]
+}
+
+func (s iniState) mergeBase() MergeBase {
+	base := MergeBase{}
+	for section, keys := range s {
+		digests := make(map[string]string, len(keys))
+		for key, values := range keys {
+			digests[key] = valuesDigest(values)
+		}
+		base[section] = digests
+	}
+	return base
+}
+
+func (f *GenericIniFile) state() iniState {
+	state := iniState{}
+	for _, section := range f.sections {
+		keys := map[string][]string{}
+		for _, key := range section.Keys() {
+			name := strings.ToLower(key.Name)
+			keys[name] = append(keys[name], key.Value)
+		}
+		state[strings.ToLower(section.Name())] = keys
+	}
+	return state
+}
+
+// MergeBase returns the digest of the values as last loaded or saved.
+func (f *GenericIniFile) MergeBase() MergeBase {
+	return f.base.mergeBase()
+}
+
+// SetMergeBase sets the digest of the values last written to the file, such as the one persisted by a previous run.
+// The next Save uses it to tell the changes made on disk apart, instead of the values as loaded.
+func (f *GenericIniFile) SetMergeBase(base MergeBase) {
+	f.mergeBase = base
+}
+
+// Conflicts returns the conflicts found by the last Save.
+func (f *GenericIniFile) Conflicts() []MergeConflict {
+	return f.conflicts
+}
+
+// mergeDiskChanges merges the changes made on disk since the file was last written, such as the ones
+// written by the server on exit, into the in-memory values. Keys changed on both sides are reported
+// as conflicts and keep their in-memory values.
+func (f *GenericIniFile) mergeDiskChanges(filePath string) error {
+	loadedHere := f.base != nil && filePath == f.loadedPath
+
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read file '%s': %v", filePath, err)
+	}
+	// A merge base may tell changes made before the file was loaded, which must still be checked
+	if loadedHere && f.mergeBase == nil && sha256.Sum256(data) == f.loadedHash {
+		return nil
+	}
+
+	f.Logger.Debug("Ini file changed on disk since it was loaded, merging",
+		"function", "mergeDiskChanges", "file", filePath)
+
+	current := NewGenericIniFile(f.name)
+	if f.explicitEnc {
+		current.SetEncoding(f.encoding)
+	}
+	if err := current.Load(filePath); err != nil {
+		return fmt.Errorf("failed to load the on-disk changes: %w", err)
+	}
+
+	var loaded iniState
+	if loadedHere {
+		loaded = f.base
+	}
+	base := f.mergeBase
+	if base == nil {
+		base = f.base.mergeBase()
+	}
+
+	f.conflicts = f.merge(base, loaded, current)
+	for _, conflict := range f.conflicts {
+		f.Logger.Warn("Ini key changed both on disk and by the launcher, keeping the launcher value",
+			"function", "mergeDiskChanges", "file", filePath, "section", conflict.Section, "key", conflict.Key,
+			"current", conflict.Current, "desired", conflict.Desired)
+	}
+	return nil
+}
+
+// merge applies the changes between base and current to the file, and returns the conflicts.
+// The in-memory changes are the ones made since the file was loaded, if it was, or since base otherwise.
+func (f *GenericIniFile) merge(base MergeBase, loaded iniState, current *GenericIniFile) []MergeConflict {
+	type keyRef struct{ section, key string }
+
+	// Every key of both versions, with the on-disk names first
+	var refs []keyRef
+	seen := map[keyRef]bool{}
+	addRefs := func(file *GenericIniFile) {
+		for _, section := range file.sections {
+			for _, key := range section.Keys() {
+				lower := keyRef{strings.ToLower(section.Name()), strings.ToLower(key.Name)}
+				if !seen[lower] {
+					seen[lower] = true
+					refs = append(refs, keyRef{section.Name(), key.Name})
+				}
+			}
+		}
+	}
+	addRefs(current)
+	addRefs(f)
+
+	desired := f.state()
+	currentState := current.state()
+
+	var conflicts []MergeConflict
+	for _, ref := range refs {
+		c := currentState.values(ref.section, ref.key)
+		d := desired.values(ref.section, ref.key)
+		b := base.digest(ref.section, ref.key)
+
+		changedInMemory := valuesDigest(d) != b
+		if loaded != nil {
+			changedInMemory = !slices.Equal(d, loaded.values(ref.section, ref.key))
+		}
+
+		switch {
+		case valuesDigest(c) == b || slices.Equal(c, d):
+			// Unchanged on disk, or changed the same way on both sides
+		case !changedInMemory:
+			// Changed on disk only
+			if c == nil {
+				f.DeleteKey(ref.section, ref.key)
+			} else {
+				f.SetKeys(ref.section, ref.key, c)
+			}
+		default:
+			conflicts = append(conflicts, MergeConflict{
+				Section: ref.section,
+				Key:     ref.key,
+				Current: c,
+				Desired: d,
+			})
+		}
+	}
+	return conflicts
+}
+
+// lockFile takes an advisory lock on a '.lock' file next to the given file
+// and returns the function releasing it.
+// The lock only serializes the launcher processes, the game server doesn't take it.
+// The lock file is left in place: removing it would let a process lock a new file
+// while another one still holds the removed one.
+func lockFile(filePath string) (func(), error) {
+	lockPath := filePath + ".lock"
+	file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file '%s': %v", lockPath, err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock file '%s': %v", lockPath, err)
+	}
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
@@ -0,0 +1,183 @@
+package ini
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type mergeTest struct {
+	name      string
+	load      string // Content loaded before the change, nothing is loaded if empty
+	change    func(file *GenericIniFile)
+	disk      string // Content written on disk before the save
+	expected  string
+	conflicts []MergeConflict
+}
+
+// runMergeTest saves a file after its content was changed on disk and checks the merge.
+func runMergeTest(t *testing.T, tt mergeTest, base MergeBase) {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "test.ini")
+	file := NewGenericIniFile("test")
+	if tt.load != "" {
+		if err := os.WriteFile(filePath, []byte(tt.load), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := file.Load(filePath); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if base != nil {
+		file.SetMergeBase(base)
+	}
+	tt.change(file)
+
+	if err := os.WriteFile(filePath, []byte(tt.disk), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Save(filePath); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved) != tt.expected {
+		t.Errorf("saved:\n%q\nexpected:\n%q", saved, tt.expected)
+	}
+	if conflicts := file.Conflicts(); len(conflicts) != 0 || len(tt.conflicts) != 0 {
+		if !reflect.DeepEqual(conflicts, tt.conflicts) {
+			t.Errorf("Conflicts() = %v, expected %v", conflicts, tt.conflicts)
+		}
+	}
+}
+
+func setTestKey(section string, key string, value string) func(file *GenericIniFile) {
+	return func(file *GenericIniFile) {
+		file.SetKey(section, key, value, true)
+	}
+}
+
+func TestMergeDiskChanges(t *testing.T) {
+	const loaded = "[A]\nK=1\nJ=1\n"
+
+	tests := []mergeTest{
+		{
+			name:     "changed on disk only",
+			load:     loaded,
+			change:   setTestKey("A", "J", "3"),
+			disk:     "[A]\nK=2\nJ=1\n",
+			expected: "[A]\nK=2\nJ=3\n",
+		},
+		{
+			name:     "changed in memory only",
+			load:     loaded,
+			change:   setTestKey("A", "K", "3"),
+			disk:     "; comment\n[A]\nK=1\nJ=1\n",
+			expected: "[A]\nK=3\nJ=1\n",
+		},
+		{
+			name:     "same change on both sides",
+			load:     loaded,
+			change:   setTestKey("A", "K", "2"),
+			disk:     "[A]\nK=2\nJ=1\n",
+			expected: "[A]\nK=2\nJ=1\n",
+		},
+		{
+			name:      "conflict keeps the in-memory value",
+			load:      loaded,
+			change:    setTestKey("A", "K", "3"),
+			disk:      "[A]\nK=2\nJ=1\n",
+			expected:  "[A]\nK=3\nJ=1\n",
+			conflicts: []MergeConflict{{Section: "A", Key: "K", Current: []string{"2"}, Desired: []string{"3"}}},
+		},
+		{
+			name:      "conflict on a key deleted in memory",
+			load:      loaded,
+			change:    func(file *GenericIniFile) { file.DeleteKey("A", "K") },
+			disk:      "[A]\nK=2\nJ=1\n",
+			expected:  "[A]\nJ=1\n",
+			conflicts: []MergeConflict{{Section: "A", Key: "K", Current: []string{"2"}}},
+		},
+		{
+			name:     "deleted on disk",
+			load:     loaded,
+			change:   setTestKey("A", "J", "3"),
+			disk:     "[A]\nJ=1\n",
+			expected: "[A]\nJ=3\n",
+		},
+		{
+			name:     "added on disk",
+			load:     loaded,
+			change:   setTestKey("A", "J", "3"),
+			disk:     "[A]\nK=1\nJ=1\nN=5\n\n[B]\nX=1\n",
+			expected: "[A]\nK=1\nJ=3\nN=5\n\n[B]\nX=1\n",
+		},
+		{
+			name:     "repeated key changed on disk",
+			load:     "[A]\nK=1\nK=2\n",
+			change:   setTestKey("B", "X", "1"),
+			disk:     "[A]\nK=1\nK=2\nK=3\n",
+			expected: "[A]\nK=1\nK=2\nK=3\n\n[B]\nX=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runMergeTest(t, tt, nil)
+		})
+	}
+}
+
+func TestSetMergeBase(t *testing.T) {
+	// Written by a previous run, then changed by the server
+	previous := loadTestIniFile(t, "[A]\nK=1\nJ=1\n").MergeBase()
+	const disk = "[A]\nK=2\nJ=1\n"
+
+	tests := []mergeTest{
+		{
+			name:     "loaded, changed on disk only",
+			load:     disk,
+			change:   setTestKey("A", "J", "3"),
+			disk:     disk,
+			expected: "[A]\nK=2\nJ=3\n",
+		},
+		{
+			name:      "loaded, conflict",
+			load:      disk,
+			change:    setTestKey("A", "K", "3"),
+			disk:      disk,
+			expected:  "[A]\nK=3\nJ=1\n",
+			conflicts: []MergeConflict{{Section: "A", Key: "K", Current: []string{"2"}, Desired: []string{"3"}}},
+		},
+		{
+			name: "not loaded, changed on disk only",
+			change: func(file *GenericIniFile) {
+				file.SetKey("A", "K", "1", true)
+				file.SetKey("A", "J", "3", true)
+			},
+			disk:     disk,
+			expected: "[A]\nK=2\nJ=3\n",
+		},
+		{
+			name: "not loaded, conflict",
+			change: func(file *GenericIniFile) {
+				file.SetKey("A", "K", "3", true)
+				file.SetKey("A", "J", "1", true)
+			},
+			disk:      disk,
+			expected:  "[A]\nK=3\nJ=1\n",
+			conflicts: []MergeConflict{{Section: "A", Key: "K", Current: []string{"2"}, Desired: []string{"3"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runMergeTest(t, tt, previous)
+		})
+	}
+}
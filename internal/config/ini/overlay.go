@@ -0,0 +1,113 @@
+package ini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Overlay fragments are ini files whose keys are applied as directives, following the Unreal conventions:
+//
+//	Key=Value   sets the key, replacing all its values
+//	+Key=Value  appends the value to a multi-value key, unless already present
+//	.Key=Value  appends the value to a multi-value key, even if already present
+//	-Key=Value  deletes the matching value of a multi-value key
+//	!Key=       deletes the key
+
+// ApplyOverlay applies the directives of an overlay fragment to the file.
+func (f *GenericIniFile) ApplyOverlay(fragment *GenericIniFile) error {
+	for _, section := range fragment.Sections() {
+		if section.Name() == "" && len(section.Keys()) > 0 {
+			return fmt.Errorf("directive '%s' found outside of a section", section.Keys()[0].Name)
+		}
+		for _, key := range section.Keys() {
+			if err := f.applyOverlayKey(section.Name(), key.Name, key.Value); err != nil {
+				return fmt.Errorf("[%s].%s: %w", section.Name(), key.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *GenericIniFile) applyOverlayKey(section string, directive string, value string) error {
+	name := strings.TrimLeft(directive, "+.-!")
+	if name == "" {
+		return fmt.Errorf("missing key name")
+	}
+
+	var ok bool
+	switch directive[0] {
+	case '+', '.':
+		sect, err := f.getOrAddSection(section)
+		if err != nil {
+			return err
+		}
+		if directive[0] == '+' {
+			sect.AddUniqueKey(name, value)
+		} else {
+			sect.AddKey(name, value)
+		}
+		ok = f.hasKeyValue(section, name, value)
+	case '-':
+		f.DeleteUniqueKey(section, name, &value, nil)
+		ok = !f.hasKeyValue(section, name, value)
+	case '!':
+		f.DeleteKey(section, name)
+		ok = !f.HasKey(section, name)
+	default:
+		ok = f.SetKeys(section, name, []string{value})
+	}
+
+	f.Logger.Debug("Applying overlay directive",
+		"function", "applyOverlayKey", "section", section, "directive", directive, "value", value)
+
+	if !ok {
+		return fmt.Errorf("failed to apply the directive with value '%s'", value)
+	}
+	return nil
+}
+
+func (f *GenericIniFile) hasKeyValue(section string, key string, value string) bool {
+	for _, v := range f.GetKeys(section, key) {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyOverlayDir applies the '*.ini' overlay fragments of a directory, in lexical order,
+// and returns their paths. A missing directory is not an error.
+func (f *GenericIniFile) ApplyOverlayDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the overlay directory '%s': %v", dir, err)
+	}
+
+	var applied []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".ini") {
+			continue
+		}
+
+		fragmentPath := filepath.Join(dir, entry.Name())
+		// Directives must be unambiguous, a malformed fragment is never applied
+		fragment := NewGenericIniFile(entry.Name())
+		fragment.SetParseMode(ParseStrict)
+		if err := fragment.Load(fragmentPath); err != nil {
+			return applied, err
+		}
+		if err := f.ApplyOverlay(fragment); err != nil {
+			return applied, fmt.Errorf("failed to apply the overlay '%s': %w", fragmentPath, err)
+		}
+
+		f.Logger.Debug("Overlay applied",
+			"function", "ApplyOverlayDir", "file", fragmentPath)
+		applied = append(applied, fragmentPath)
+	}
+	return applied, nil
+}
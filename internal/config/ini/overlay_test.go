@@ -0,0 +1,179 @@
+package ini
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// savedContent saves the file to a temporary path and returns its content.
+func savedContent(t *testing.T, file *GenericIniFile) string {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "saved.ini")
+	if err := file.Save(filePath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestApplyOverlay(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		fragment string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "set replaces all the values",
+			content:  "[A]\nK=1\nK=2\n",
+			fragment: "[A]\nK=3\n",
+			expected: "[A]\nK=3\n",
+		},
+		{
+			name:     "set adds the section",
+			content:  "[A]\nK=1\n",
+			fragment: "[B]\nX=1\n",
+			expected: "[A]\nK=1\n\n[B]\nX=1\n",
+		},
+		{
+			name:     "append skips present values",
+			content:  "[A]\nK=1\n",
+			fragment: "[A]\n+K=2\n+K=1\n",
+			expected: "[A]\nK=1\nK=2\n",
+		},
+		{
+			name:     "append to a new section",
+			content:  "[A]\nK=1\n",
+			fragment: "[B]\n+X=1\n",
+			expected: "[A]\nK=1\n\n[B]\nX=1\n",
+		},
+		{
+			name:     "unconditional append keeps duplicates",
+			content:  "[A]\nK=1\n",
+			fragment: "[A]\n.K=1\n.K=2\n",
+			expected: "[A]\nK=1\nK=1\nK=2\n",
+		},
+		{
+			name:     "delete value",
+			content:  "[A]\nK=1\nK=2\nK=3\n",
+			fragment: "[A]\n-K=2\n",
+			expected: "[A]\nK=1\nK=3\n",
+		},
+		{
+			name:     "delete missing value",
+			content:  "[A]\nK=1\n",
+			fragment: "[A]\n-K=2\n",
+			expected: "[A]\nK=1\n",
+		},
+		{
+			name:     "delete key",
+			content:  "[A]\nK=1\nK=2\nJ=1\n",
+			fragment: "[A]\n!K=\n",
+			expected: "[A]\nJ=1\n",
+		},
+		{
+			name:     "directives apply in order",
+			content:  "[A]\nK=1\n",
+			fragment: "[A]\n!K=\n+K=2\n.K=3\n",
+			expected: "[A]\nK=2\nK=3\n",
+		},
+		{
+			name:     "directive outside a section",
+			content:  "[A]\nK=1\n",
+			fragment: "K=2\n[A]\nJ=1\n",
+			wantErr:  true,
+		},
+		{
+			name:     "missing key name",
+			content:  "[A]\nK=1\n",
+			fragment: "[A]\n+=1\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := loadTestIniFile(t, tt.content)
+			fragment := loadTestIniFile(t, tt.fragment)
+
+			err := file.ApplyOverlay(fragment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyOverlay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if actual := savedContent(t, file); actual != tt.expected {
+				t.Errorf("ApplyOverlay() wrote:\n%q\nexpected:\n%q", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyOverlayDir(t *testing.T) {
+	writeFragments := func(t *testing.T, fragments map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range fragments {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+
+	t.Run("lexical order", func(t *testing.T) {
+		dir := writeFragments(t, map[string]string{
+			"20-last.ini":  "[A]\nK=last\n+L=2\n",
+			"10-first.ini": "[A]\nK=first\n+L=1\n",
+			"30-notes.txt": "[A]\nK=ignored\n",
+		})
+		if err := os.Mkdir(filepath.Join(dir, "40-dir.ini"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		file := loadTestIniFile(t, "[A]\nK=0\n")
+		applied, err := file.ApplyOverlayDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedApplied := []string{filepath.Join(dir, "10-first.ini"), filepath.Join(dir, "20-last.ini")}
+		if !reflect.DeepEqual(applied, expectedApplied) {
+			t.Errorf("ApplyOverlayDir() applied %v, expected %v", applied, expectedApplied)
+		}
+		if actual, expected := savedContent(t, file), "[A]\nK=last\nL=1\nL=2\n"; actual != expected {
+			t.Errorf("ApplyOverlayDir() wrote:\n%q\nexpected:\n%q", actual, expected)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		file := loadTestIniFile(t, "[A]\nK=0\n")
+		applied, err := file.ApplyOverlayDir(filepath.Join(t.TempDir(), "missing"))
+		if err != nil || applied != nil {
+			t.Errorf("ApplyOverlayDir() = %v, %v, expected nothing applied", applied, err)
+		}
+	})
+
+	t.Run("directive outside a section", func(t *testing.T) {
+		dir := writeFragments(t, map[string]string{
+			"10-valid.ini":   "[A]\nK=1\n",
+			"20-invalid.ini": "K=2\n",
+		})
+
+		file := loadTestIniFile(t, "[A]\nK=0\n")
+		applied, err := file.ApplyOverlayDir(dir)
+		if err == nil {
+			t.Fatal("ApplyOverlayDir() returned no error")
+		}
+		if expected := []string{filepath.Join(dir, "10-valid.ini")}; !reflect.DeepEqual(applied, expected) {
+			t.Errorf("ApplyOverlayDir() applied %v, expected %v", applied, expected)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package ini
+
+import "fmt"
+
+// ParseMode sets how Load handles the malformed lines of an ini file.
+type ParseMode int
+
+const (
+	// ParseLenient merges the duplicate sections, keeps the unparseable lines verbatim,
+	// reads the keys found before the first section into the unnamed section and reports
+	// all of them as warnings.
+	ParseLenient ParseMode = iota
+	// ParseStrict parses the file the same way, but fails if there is any warning.
+	ParseStrict
+)
+
+// ParseWarning is a problem found while loading an ini file.
+type ParseWarning struct {
+	File    string
+	Line    int
+	Content string
+	Message string
+}
+
+func (w ParseWarning) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", w.File, w.Line, w.Message, w.Content)
+}
+
+func (w ParseWarning) Error() string {
+	return w.String()
+}
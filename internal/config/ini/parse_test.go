@@ -0,0 +1,85 @@
+package ini
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Content with every issue tolerated by the lenient mode
+const malformedContent = "Orphan=0\n[A]\nK=1\nnot a key\n\n[A]\nJ=2\n"
+
+func writeTestIniFile(t *testing.T, content string) string {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "test.ini")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestLoadLenient(t *testing.T) {
+	filePath := writeTestIniFile(t, malformedContent)
+
+	file := NewGenericIniFile("test")
+	if err := file.Load(filePath); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ParseWarning{
+		{File: filePath, Line: 1, Content: "Orphan=0", Message: "key found outside of a section"},
+		{File: filePath, Line: 4, Content: "not a key", Message: "invalid line kept as is"},
+		{File: filePath, Line: 6, Content: "[A]", Message: "duplicate section merged"},
+	}
+	if warnings := file.Warnings(); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("Warnings() = %v, expected %v", warnings, expected)
+	}
+	if expected := fmt.Sprintf("%s:4: invalid line kept as is: not a key", filePath); file.Warnings()[1].String() != expected {
+		t.Errorf("String() = %s, expected %s", file.Warnings()[1], expected)
+	}
+
+	// The duplicate section is merged into the first one, the invalid line is kept
+	if names := keyNames(file.GetSection("A")); !reflect.DeepEqual(names, []string{"K", "J"}) {
+		t.Errorf("merged section keys = %v, expected [K J]", names)
+	}
+	if value := file.GetKey("", "Orphan", ""); value != "0" {
+		t.Errorf("GetKey() = %s, expected the key outside of a section", value)
+	}
+	if actual, expected := savedContent(t, file), "Orphan=0\n[A]\nK=1\nnot a key\n\nJ=2\n"; actual != expected {
+		t.Errorf("saved:\n%q\nexpected:\n%q", actual, expected)
+	}
+}
+
+func TestLoadStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lines   []int
+	}{
+		{"valid", "[A]\nK=1\n; comment\n\n[B]\nX=1\n", nil},
+		{"every issue", malformedContent, []int{1, 4, 6}},
+		{"crlf", "[A]\r\nK=1\r\n[A]\r\nJ=2\r\n", []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := writeTestIniFile(t, tt.content)
+
+			file := NewGenericIniFile("test")
+			file.SetParseMode(ParseStrict)
+			err := file.Load(filePath)
+			if (err != nil) != (len(tt.lines) > 0) {
+				t.Fatalf("Load() error = %v, expected issues on lines %v", err, tt.lines)
+			}
+			for _, line := range tt.lines {
+				if location := fmt.Sprintf("%s:%d:", filePath, line); err != nil && !strings.Contains(err.Error(), location) {
+					t.Errorf("Load() error doesn't report %s:\n%v", location, err)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,16 @@
 package ini
 
+import (
+	"slices"
+	"strings"
+)
+
 type IniSection struct {
-	name string
-	keys []*IniKey // Slice to maintain order and support duplicates
+	name   string
+	keys   []*IniKey // Slice to maintain order and support duplicates
+	before []string  // Comment and blank lines preceding the section header
+	header string    // Original section header line
+	after  []string  // Comment and blank lines following the keys of the last section of the file
 }
 
 func NewIniSection(name string) *IniSection {
@@ -22,7 +30,7 @@ func (s *IniSection) Keys() []*IniKey {
 
 func (s *IniSection) GetKey(name string) (string, bool) {
 	for _, key := range s.keys {
-		if key.Name == name {
+		if key.HasName(name) {
 			return key.Value, true
 		}
 	}
@@ -32,7 +40,7 @@ func (s *IniSection) GetKey(name string) (string, bool) {
 func (s *IniSection) GetKeys(name string) []string {
 	var values []string
 	for _, key := range s.keys {
-		if key.Name == name {
+		if key.HasName(name) {
 			values = append(values, key.Value)
 		}
 	}
@@ -40,13 +48,27 @@ func (s *IniSection) GetKeys(name string) []string {
 }
 
 func (s *IniSection) AddKey(name, value string) {
-	s.keys = append(s.keys, &IniKey{Name: name, Value: value})
+	s.addKey(name, value)
+}
+
+func (s *IniSection) addKey(name, value string) *IniKey {
+	key := &IniKey{Name: name, Value: value}
+	s.keys = append(s.keys, key)
 	s.recalculateIndices()
+	return key
+}
+
+// HeaderLine returns the section header line, keeping the original layout.
+func (s *IniSection) HeaderLine() string {
+	if s.header != "" {
+		return s.header
+	}
+	return "[" + s.name + "]"
 }
 
 func (s *IniSection) AddUniqueKey(name, value string) {
 	for _, key := range s.keys {
-		if key.Name == name && key.Value == value {
+		if key.HasName(name) && key.Value == value {
 			return
 		}
 	}
@@ -56,7 +78,7 @@ func (s *IniSection) AddUniqueKey(name, value string) {
 func (s *IniSection) DeleteKey(name string) {
 	newKeys := []*IniKey{}
 	for _, key := range s.keys {
-		if key.Name != name {
+		if !key.HasName(name) {
 			newKeys = append(newKeys, key)
 		}
 	}
@@ -67,7 +89,7 @@ func (s *IniSection) DeleteKey(name string) {
 func (s *IniSection) DeleteUniqueKey(name string, targetValue *string, targetIndex *int) {
 	newKeys := []*IniKey{}
 	for i, key := range s.keys {
-		if key.Name == name {
+		if key.HasName(name) {
 			if targetValue != nil && key.Value == *targetValue {
 				continue
 			}
@@ -87,13 +109,13 @@ func (s *IniSection) SetKey(name, value string) {
 
 func (s *IniSection) SetUniqueKey(name, value string) {
 	for _, key := range s.keys {
-		if key.Name == name && key.Value == value {
+		if key.HasName(name) && key.Value == value {
 			return
 		}
 	}
 
 	for _, key := range s.keys {
-		if key.Name == name {
+		if key.HasName(name) {
 			key.Value = value
 			return
 		}
@@ -101,6 +123,84 @@ func (s *IniSection) SetUniqueKey(name, value string) {
 	s.AddKey(name, value)
 }
 
+// ReplaceKeys replaces the values of a multi-value key, updating the existing keys in place,
+// appending the extra values and deleting the surplus keys.
+func (s *IniSection) ReplaceKeys(name string, values []string) {
+	i := 0
+	newKeys := []*IniKey{}
+	for _, key := range s.keys {
+		if key.HasName(name) {
+			if i >= len(values) {
+				continue
+			}
+			key.Value = values[i]
+			i++
+		}
+		newKeys = append(newKeys, key)
+	}
+	s.keys = newKeys
+
+	for ; i < len(values); i++ {
+		s.keys = append(s.keys, &IniKey{Name: name, Value: values[i]})
+	}
+	s.recalculateIndices()
+}
+
+// GetIndexedKey returns the value of an indexed key such as "Key[3]".
+func (s *IniSection) GetIndexedKey(name string, index int) (string, bool) {
+	for _, key := range s.keys {
+		if keyName, keyIndex, ok := ParseIndexedKey(key.Name); ok && strings.EqualFold(keyName, name) && keyIndex == index {
+			return key.Value, true
+		}
+	}
+	return "", false
+}
+
+// GetIndexedKeys returns the values of an indexed key by index.
+func (s *IniSection) GetIndexedKeys(name string) map[int]string {
+	values := map[int]string{}
+	for _, key := range s.keys {
+		if keyName, keyIndex, ok := ParseIndexedKey(key.Name); ok && strings.EqualFold(keyName, name) {
+			values[keyIndex] = key.Value
+		}
+	}
+	return values
+}
+
+// SetIndexedKey sets the value of an indexed key, adding it next to the other indexes if needed.
+func (s *IniSection) SetIndexedKey(name string, index int, value string) {
+	pos := -1
+	for i, key := range s.keys {
+		keyName, keyIndex, ok := ParseIndexedKey(key.Name)
+		if !ok || !strings.EqualFold(keyName, name) {
+			continue
+		}
+		if keyIndex == index {
+			key.Value = value
+			return
+		}
+		// Keep the indexes in order
+		if keyIndex < index || pos < 0 {
+			pos = i
+			if keyIndex < index {
+				pos++
+			}
+		}
+	}
+
+	key := &IniKey{Name: IndexedKeyName(name, index), Value: value}
+	if pos < 0 {
+		s.keys = append(s.keys, key)
+	} else {
+		s.keys = slices.Insert(s.keys, pos, key)
+	}
+	s.recalculateIndices()
+}
+
+func (s *IniSection) DeleteIndexedKey(name string, index int) {
+	s.DeleteKey(IndexedKeyName(name, index))
+}
+
 func (s *IniSection) recalculateIndices() {
 	for i, key := range s.keys {
 		key.Index = i
@@ -0,0 +1,104 @@
+package ini
+
+import (
+	"slices"
+	"testing"
+)
+
+// keyNames returns the names of the keys of a section, in order.
+func keyNames(section *IniSection) []string {
+	var names []string
+	for _, key := range section.Keys() {
+		names = append(names, key.Name)
+	}
+	return names
+}
+
+func TestSetIndexedKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		index    int
+		expected []string
+	}{
+		{"between indexes", []string{"Key[0]", "Key[3]", "Key[5]"}, 4, []string{"Key[0]", "Key[3]", "Key[4]", "Key[5]"}},
+		{"before every index", []string{"Key[3]", "Key[5]"}, 1, []string{"Key[1]", "Key[3]", "Key[5]"}},
+		{"after every index", []string{"Key[0]", "Key[3]", "Key[5]"}, 6, []string{"Key[0]", "Key[3]", "Key[5]", "Key[6]"}},
+		{"next to its indexes", []string{"Key[0]", "Other", "Key[3]"}, 1, []string{"Key[0]", "Key[1]", "Other", "Key[3]"}},
+		{"existing index", []string{"Key[0]", "Key[3]", "Key[5]"}, 3, []string{"Key[0]", "Key[3]", "Key[5]"}},
+		{"case-insensitive name", []string{"key[0]", "KEY[5]"}, 2, []string{"key[0]", "Key[2]", "KEY[5]"}},
+		{"no index yet", []string{"Other"}, 2, []string{"Other", "Key[2]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			section := NewIniSection("A")
+			for _, name := range tt.keys {
+				section.AddKey(name, "old")
+			}
+
+			section.SetIndexedKey("Key", tt.index, "new")
+
+			if actual := keyNames(section); !slices.Equal(actual, tt.expected) {
+				t.Errorf("keys = %v, expected %v", actual, tt.expected)
+			}
+			if value, ok := section.GetIndexedKey("key", tt.index); !ok || value != "new" {
+				t.Errorf("GetIndexedKey(%d) = %s, %v, expected new", tt.index, value, ok)
+			}
+			for i, key := range section.Keys() {
+				if key.Index != i {
+					t.Errorf("key %s has index %d, expected %d", key.Name, key.Index, i)
+				}
+			}
+		})
+	}
+}
+
+func TestCaseInsensitiveNames(t *testing.T) {
+	file := loadTestIniFile(t, "[Engine.GameInfo]\nGoreLevel=0\nMutator=A\nmutator=B\n\n[Other]\nX=1\n")
+
+	section := file.GetSection("engine.gameinfo")
+	if section == nil || section.Name() != "Engine.GameInfo" {
+		t.Fatalf("GetSection() = %v, expected the Engine.GameInfo section", section)
+	}
+	if value, ok := section.GetKey("GORELEVEL"); !ok || value != "0" {
+		t.Errorf("IniSection.GetKey() = %s, %v, expected 0", value, ok)
+	}
+	if values := section.GetKeys("MUTATOR"); !slices.Equal(values, []string{"A", "B"}) {
+		t.Errorf("IniSection.GetKeys() = %v, expected [A B]", values)
+	}
+	if value := file.GetKey("ENGINE.GAMEINFO", "gorelevel", ""); value != "0" {
+		t.Errorf("GetKey() = %s, expected 0", value)
+	}
+	if _, err := file.AddSection("engine.GAMEINFO"); err == nil {
+		t.Error("AddSection() added a section differing in case only")
+	}
+
+	// The names keep their original case
+	file.SetKey("engine.gameinfo", "GORELEVEL", "2", true)
+	section.DeleteKey("MUTATOR")
+	if !file.DeleteSection("OTHER") {
+		t.Error("DeleteSection() didn't find the Other section")
+	}
+	if file.GetSection("Other") != nil {
+		t.Error("GetSection() found the deleted section")
+	}
+
+	if actual, expected := savedContent(t, file), "[Engine.GameInfo]\nGoreLevel=2\n"; actual != expected {
+		t.Errorf("saved:\n%q\nexpected:\n%q", actual, expected)
+	}
+}
+
+func TestDuplicateSectionsDifferingInCase(t *testing.T) {
+	file := loadTestIniFile(t, "[Engine.GameInfo]\nGoreLevel=0\n\n[engine.gameinfo]\nbChangeLevels=True\n")
+
+	if len(file.Sections()) != 1 {
+		t.Fatalf("found %d sections, expected 1", len(file.Sections()))
+	}
+	if value := file.GetKey("Engine.GameInfo", "bChangeLevels", ""); value != "True" {
+		t.Errorf("GetKey() = %s, expected the merged key value", value)
+	}
+	if actual, expected := savedContent(t, file), "[Engine.GameInfo]\nGoreLevel=0\n\nbChangeLevels=True\n"; actual != expected {
+		t.Errorf("saved:\n%q\nexpected:\n%q", actual, expected)
+	}
+}
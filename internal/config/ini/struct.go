@@ -0,0 +1,151 @@
+package ini
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// StructField is a field of an Unreal struct value. Its value is kept as written.
+type StructField struct {
+	Name  string
+	Value string
+}
+
+// StructValue is an Unreal struct value such as '(GameClass="KFmod.KFGameType",Prefix="KF")'.
+// Fields are kept in order and looked up case-insensitively.
+type StructValue struct {
+	fields []*StructField
+}
+
+func NewStructValue() *StructValue {
+	return &StructValue{fields: []*StructField{}}
+}
+
+// ParseStruct parses a parenthesised struct value.
+func ParseStruct(value string) (*StructValue, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return nil, fmt.Errorf("not a struct value: %s", value)
+	}
+
+	sv := NewStructValue()
+	inner := strings.TrimSpace(value[1 : len(value)-1])
+	if inner == "" {
+		return sv, nil
+	}
+
+	parts, err := splitTopLevel(inner, ',')
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		name, fieldValue, found := strings.Cut(part, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid struct field '%s' in %s", part, value)
+		}
+		sv.fields = append(sv.fields, &StructField{Name: name, Value: strings.TrimSpace(fieldValue)})
+	}
+	return sv, nil
+}
+
+func (sv *StructValue) Fields() []*StructField {
+	return sv.fields
+}
+
+func (sv *StructValue) field(name string) *StructField {
+	for _, field := range sv.fields {
+		if strings.EqualFold(field.Name, name) {
+			return field
+		}
+	}
+	return nil
+}
+
+// Get returns the raw value of a field.
+func (sv *StructValue) Get(name string) (string, bool) {
+	if field := sv.field(name); field != nil {
+		return field.Value, true
+	}
+	return "", false
+}
+
+// GetString returns the unquoted value of a field.
+func (sv *StructValue) GetString(name string, defvalue string) string {
+	if value, exists := sv.Get(name); exists {
+		return Unquote(value)
+	}
+	return defvalue
+}
+
+func (sv *StructValue) GetBool(name string, defvalue bool) bool {
+	value, _ := sv.Get(name)
+	if result, err := cast.ToBoolE(value); err == nil {
+		return result
+	}
+	return defvalue
+}
+
+func (sv *StructValue) GetInt(name string, defvalue int) int {
+	value, _ := sv.Get(name)
+	if result, err := cast.ToIntE(value); err == nil {
+		return result
+	}
+	return defvalue
+}
+
+func (sv *StructValue) GetFloat(name string, defvalue float64) float64 {
+	value, _ := sv.Get(name)
+	if result, err := cast.ToFloat64E(value); err == nil {
+		return result
+	}
+	return defvalue
+}
+
+// GetStruct returns the value of a nested struct field.
+func (sv *StructValue) GetStruct(name string) (*StructValue, error) {
+	value, exists := sv.Get(name)
+	if !exists {
+		return nil, fmt.Errorf("struct field not found: %s", name)
+	}
+	return ParseStruct(value)
+}
+
+// Set sets the raw value of a field, adding the field if needed.
+func (sv *StructValue) Set(name string, value any) {
+	val := cast.ToString(value)
+	if field := sv.field(name); field != nil {
+		field.Value = val
+		return
+	}
+	sv.fields = append(sv.fields, &StructField{Name: name, Value: val})
+}
+
+// SetString sets the quoted value of a field.
+func (sv *StructValue) SetString(name string, value string) {
+	sv.Set(name, Quote(value))
+}
+
+func (sv *StructValue) SetStruct(name string, value *StructValue) {
+	sv.Set(name, value.String())
+}
+
+func (sv *StructValue) Delete(name string) bool {
+	for i, field := range sv.fields {
+		if strings.EqualFold(field.Name, name) {
+			sv.fields = append(sv.fields[:i], sv.fields[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (sv *StructValue) String() string {
+	parts := make([]string, len(sv.fields))
+	for i, field := range sv.fields {
+		parts[i] = field.Name + "=" + field.Value
+	}
+	return "(" + strings.Join(parts, ",") + ")"
+}
@@ -0,0 +1,106 @@
+package ini
+
+import "testing"
+
+func TestParseStruct(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected [][2]string
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			value:    "()",
+			expected: [][2]string{},
+		},
+		{
+			name:     "plain fields",
+			value:    "(Count=2, Enabled=True)",
+			expected: [][2]string{{"Count", "2"}, {"Enabled", "True"}},
+		},
+		{
+			name:     "quoted fields",
+			value:    `(GameClass="KFmod.KFGameType",Name="a, b (c) = \"d\"")`,
+			expected: [][2]string{{"GameClass", `"KFmod.KFGameType"`}, {"Name", `"a, b (c) = \"d\""`}},
+		},
+		{
+			name:     "nested struct",
+			value:    `(Name="Wave",Inner=(A=1,B=(C="x,y")),Last=3)`,
+			expected: [][2]string{{"Name", `"Wave"`}, {"Inner", `(A=1,B=(C="x,y"))`}, {"Last", "3"}},
+		},
+		{
+			name:    "not a struct",
+			value:   "Name=1",
+			wantErr: true,
+		},
+		{
+			name:    "missing field name",
+			value:   "(=1)",
+			wantErr: true,
+		},
+		{
+			name:    "unbalanced parentheses",
+			value:   "(A=(B=1)",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quoted string",
+			value:   `(A="x)`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sv, err := ParseStruct(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStruct() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			fields := sv.Fields()
+			if len(fields) != len(tt.expected) {
+				t.Fatalf("ParseStruct() = %s, expected %d fields", sv, len(tt.expected))
+			}
+			for i, field := range fields {
+				if field.Name != tt.expected[i][0] || field.Value != tt.expected[i][1] {
+					t.Errorf("field %d = %s=%s, expected %s=%s", i, field.Name, field.Value, tt.expected[i][0], tt.expected[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestStructValueAccessors(t *testing.T) {
+	sv := mustParseStruct(t, `(Name="Say \"hi\"",Inner=(Level=3,Title="a,b"),Rate=0.5)`)
+
+	if actual := sv.GetString("name", ""); actual != `Say "hi"` {
+		t.Errorf("GetString() = %q", actual)
+	}
+	if actual := sv.GetFloat("Rate", 0); actual != 0.5 {
+		t.Errorf("GetFloat() = %v", actual)
+	}
+
+	inner, err := sv.GetStruct("Inner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inner.GetInt("Level", 0) != 3 || inner.GetString("Title", "") != "a,b" {
+		t.Errorf("GetStruct() = %s", inner)
+	}
+
+	// Nested and quoted values survive a round trip
+	inner.Set("Level", 4)
+	sv.SetStruct("Inner", inner)
+	sv.SetString("Name", `C:\KF "x"`)
+	expected := `(Name="C:\\KF \"x\"",Inner=(Level=4,Title="a,b"),Rate=0.5)`
+	if sv.String() != expected {
+		t.Errorf("String() = %s, expected %s", sv, expected)
+	}
+	if reparsed := mustParseStruct(t, sv.String()); reparsed.String() != expected || reparsed.GetString("Name", "") != `C:\KF "x"` {
+		t.Errorf("round trip = %s, expected %s", reparsed, expected)
+	}
+}
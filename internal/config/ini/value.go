@@ -0,0 +1,123 @@
+package ini
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Quote returns s as an Unreal quoted string, escaping the quotes and backslashes.
+func Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// Unquote returns the content of an Unreal quoted string.
+// Values which aren't quoted are returned unchanged.
+func Unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, r := range s[1 : len(s)-1] {
+		if escaped {
+			switch r {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			default:
+				b.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// IsQuoted reports whether s is an Unreal quoted string.
+func IsQuoted(s string) bool {
+	return len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"'
+}
+
+// ParseIndexedKey splits an indexed key name such as "Key[3]" into its name and index.
+func ParseIndexedKey(name string) (string, int, bool) {
+	open := strings.IndexByte(name, '[')
+	if open <= 0 || !strings.HasSuffix(name, "]") {
+		return name, 0, false
+	}
+
+	index, err := strconv.Atoi(name[open+1 : len(name)-1])
+	if err != nil || index < 0 {
+		return name, 0, false
+	}
+	return name[:open], index, true
+}
+
+// IndexedKeyName returns the indexed key name of the given index, e.g. "Key[3]".
+func IndexedKeyName(name string, index int) string {
+	return fmt.Sprintf("%s[%d]", name, index)
+}
+
+// splitTopLevel splits s on the separator, ignoring the separators inside quotes and parentheses.
+func splitTopLevel(s string, sep byte) ([]string, error) {
+	var parts []string
+	depth := 0
+	inQuotes := false
+	escaped := false
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes && c == '\\':
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in '%s'", s)
+			}
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quoted string in '%s'", s)
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in '%s'", s)
+	}
+	return append(parts, s[start:]), nil
+}
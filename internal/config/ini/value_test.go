@@ -0,0 +1,82 @@
+package ini
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"plain", "KF Server", `"KF Server"`},
+		{"empty", "", `""`},
+		{"quotes", `say "hi"`, `"say \"hi\""`},
+		{"backslashes", `C:\KF\Maps`, `"C:\\KF\\Maps"`},
+		{"escaped quote", `\"`, `"\\\""`},
+		{"newline and tab", "a\nb\tc", `"a\nb\tc"`},
+		{"unicode", "Café", `"Café"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quoted := Quote(tt.value)
+			if quoted != tt.expected {
+				t.Errorf("Quote(%q) = %s, expected %s", tt.value, quoted, tt.expected)
+			}
+			if !IsQuoted(quoted) {
+				t.Errorf("IsQuoted(%s) = false", quoted)
+			}
+			if actual := Unquote(quoted); actual != tt.value {
+				t.Errorf("Unquote(%s) = %q, expected %q", quoted, actual, tt.value)
+			}
+		})
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"not quoted", "KF Server", "KF Server"},
+		{"single quote character", `"`, `"`},
+		{"opening quote only", `"KF`, `"KF`},
+		{"unknown escape", `"\a"`, "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := Unquote(tt.value); actual != tt.expected {
+				t.Errorf("Unquote(%s) = %q, expected %q", tt.value, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseIndexedKey(t *testing.T) {
+	tests := []struct {
+		key           string
+		expectedName  string
+		expectedIndex int
+		expectedOk    bool
+	}{
+		{"Key[0]", "Key", 0, true},
+		{"Key[12]", "Key", 12, true},
+		{"Key", "Key", 0, false},
+		{"Key[]", "Key[]", 0, false},
+		{"Key[-1]", "Key[-1]", 0, false},
+		{"Key[a]", "Key[a]", 0, false},
+		{"[0]", "[0]", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			name, index, ok := ParseIndexedKey(tt.key)
+			if name != tt.expectedName || index != tt.expectedIndex || ok != tt.expectedOk {
+				t.Errorf("ParseIndexedKey(%s) = %s, %d, %v, expected %s, %d, %v",
+					tt.key, name, index, ok, tt.expectedName, tt.expectedIndex, tt.expectedOk)
+			}
+		})
+	}
+}
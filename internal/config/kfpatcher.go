@@ -1,6 +1,8 @@
 package config
 
 import (
+	"reflect"
+
 	"github.com/K4rian/kfdsl/internal/config/ini"
 	"github.com/K4rian/kfdsl/internal/settings"
 )
@@ -10,17 +12,14 @@ type KFPIniFile struct {
 	filePath string
 }
 
-const (
-	// Sections
-	kfpRootSection = "KFPatcher.Settings"
-
-	// Keys
-	kfpKeyShowPerk          = "bShowPerk"
-	kfpKeyAllowZedTime      = "bAllowZedTime"
-	kfpKeyAllTradersOpen    = "bAllTradersOpen"
-	kfpKeyAllTradersMessage = "bAllTradersMessage"
-	kfpKeyBuyEverywhere     = "bBuyEverywhere"
-)
+// kfpSettings holds the KFPatcher settings managed by the launcher.
+type kfpSettings struct {
+	ShowPerk          bool   `ini:"KFPatcher.Settings,bShowPerk"`
+	AllowZedTime      bool   `ini:"KFPatcher.Settings,bAllowZedTime"`
+	AllTradersOpen    bool   `ini:"KFPatcher.Settings,bAllTradersOpen"`
+	AllTradersMessage string `ini:"KFPatcher.Settings,bAllTradersMessage"`
+	BuyEverywhere     bool   `ini:"KFPatcher.Settings,bBuyEverywhere"`
+}
 
 func NewKFPIniFile(filePath string) (*KFPIniFile, error) {
 	kfpIniFile := &KFPIniFile{
@@ -51,42 +50,95 @@ func NewKFPIniFile(filePath string) (*KFPIniFile, error) {
 
 // fRefreshTime
 
+// readSettings reads the KFPatcher settings. Missing keys take their default value.
+func (kf *KFPIniFile) readSettings() (kfpSettings, error) {
+	kfps := kfpSettings{
+		ShowPerk:          !settings.DefaultKFPHidePerks,
+		AllowZedTime:      !settings.DefaultKFPDisableZedTime,
+		AllTradersOpen:    settings.DefaultKFPEnableAllTraders,
+		AllTradersMessage: settings.DefaultKFPAllTradersMessage,
+		BuyEverywhere:     settings.DefaultKFPBuyEverywhere,
+	}
+	err := ini.Unmarshal(kf.GenericIniFile, &kfps)
+	return kfps, err
+}
+
+// settings reads the KFPatcher settings. Missing and invalid keys take their default value.
+func (kf *KFPIniFile) settings() kfpSettings {
+	kfps, err := kf.readSettings()
+	if err != nil {
+		kf.Logger.Warn("Invalid KFPatcher settings, using the default values instead",
+			"function", "settings", "file", kf.filePath, "error", err)
+	}
+	return kfps
+}
+
+// updateSettings applies a change to the KFPatcher settings and writes back the changed ones only.
+// Nothing is written if the settings can't be read, so an invalid value is never silently replaced.
+func (kf *KFPIniFile) updateSettings(change func(kfps *kfpSettings)) bool {
+	kfps, err := kf.readSettings()
+	if err != nil {
+		kf.Logger.Warn("Invalid KFPatcher settings, leaving them unchanged",
+			"function", "updateSettings", "file", kf.filePath, "error", err)
+		return false
+	}
+	updated := kfps
+	change(&updated)
+
+	current, desired := reflect.ValueOf(kfps), reflect.ValueOf(updated)
+	for i := 0; i < current.NumField(); i++ {
+		if current.Field(i).Equal(desired.Field(i)) {
+			continue
+		}
+
+		// A struct holding the changed field alone, with its tag
+		changed := reflect.New(reflect.StructOf([]reflect.StructField{current.Type().Field(i)}))
+		changed.Elem().Field(0).Set(desired.Field(i))
+		if err := ini.Marshal(changed.Interface(), kf.GenericIniFile); err != nil {
+			kf.Logger.Warn("Failed to write the KFPatcher settings",
+				"function", "updateSettings", "file", kf.filePath, "error", err)
+			return false
+		}
+	}
+	return true
+}
+
 func (kf *KFPIniFile) IsShowPerksEnabled() bool {
-	return kf.GetKeyBool(kfpRootSection, kfpKeyShowPerk, !settings.DefaultKFPHidePerks)
+	return kf.settings().ShowPerk
 }
 
 func (kf *KFPIniFile) IsZEDTimeEnabled() bool {
-	return kf.GetKeyBool(kfpRootSection, kfpKeyAllowZedTime, !settings.DefaultKFPDisableZedTime)
+	return kf.settings().AllowZedTime
 }
 
 func (kf *KFPIniFile) IsAllTradersOpenEnabled() bool {
-	return kf.GetKeyBool(kfpRootSection, kfpKeyAllTradersOpen, settings.DefaultKFPEnableAllTraders)
+	return kf.settings().AllTradersOpen
 }
 
 func (kf *KFPIniFile) GetAllTradersMessage() string {
-	return kf.GetKey(kfpRootSection, kfpKeyAllTradersMessage, settings.DefaultKFPAllTradersMessage)
+	return kf.settings().AllTradersMessage
 }
 
 func (kf *KFPIniFile) IsBuyEverywhereEnabled() bool {
-	return kf.GetKeyBool(kfpRootSection, kfpKeyBuyEverywhere, settings.DefaultKFPBuyEverywhere)
+	return kf.settings().BuyEverywhere
 }
 
 func (kf *KFPIniFile) SetShowPerksEnabled(enabled bool) bool {
-	return kf.SetKeyBool(kfpRootSection, kfpKeyShowPerk, enabled, true)
+	return kf.updateSettings(func(kfps *kfpSettings) { kfps.ShowPerk = enabled })
 }
 
 func (kf *KFPIniFile) SetZEDTimeEnabled(enabled bool) bool {
-	return kf.SetKeyBool(kfpRootSection, kfpKeyAllowZedTime, enabled, true)
+	return kf.updateSettings(func(kfps *kfpSettings) { kfps.AllowZedTime = enabled })
 }
 
 func (kf *KFPIniFile) SetAllTradersOpenEnabled(enabled bool) bool {
-	return kf.SetKeyBool(kfpRootSection, kfpKeyAllTradersOpen, enabled, true)
+	return kf.updateSettings(func(kfps *kfpSettings) { kfps.AllTradersOpen = enabled })
 }
 
 func (kf *KFPIniFile) SetAllTradersMessage(message string) bool {
-	return kf.SetKey(kfpRootSection, kfpKeyAllTradersMessage, message, true)
+	return kf.updateSettings(func(kfps *kfpSettings) { kfps.AllTradersMessage = message })
 }
 
 func (kf *KFPIniFile) SetBuyEverywhereEnabled(enabled bool) bool {
-	return kf.SetKeyBool(kfpRootSection, kfpKeyBuyEverywhere, enabled, true)
+	return kf.updateSettings(func(kfps *kfpSettings) { kfps.BuyEverywhere = enabled })
 }
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/K4rian/kfdsl/internal/log"
+)
+
+func TestMain(m *testing.M) {
+	log.Init("error", "", "text", 1, 1, 1, false)
+	os.Exit(m.Run())
+}
+
+// loadTestKFPIniFile loads a KFPatcher settings file from its content, and returns it with its path.
+func loadTestKFPIniFile(t *testing.T, content string) (*KFPIniFile, string) {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "KFPatcherSettings.ini")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := NewKFPIniFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file, filePath
+}
+
+func TestKFPIniFileSetters(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		set      func(file *KFPIniFile) bool
+		wantOk   bool
+		expected string
+	}{
+		{
+			name:     "only the changed key is written",
+			content:  "[KFPatcher.Settings]\nbShowPerk=True\n",
+			set:      func(file *KFPIniFile) bool { return file.SetZEDTimeEnabled(false) },
+			wantOk:   true,
+			expected: "[KFPatcher.Settings]\nbShowPerk=True\nbAllowZedTime=false\n",
+		},
+		{
+			name:     "unchanged value",
+			content:  "[KFPatcher.Settings]\nbShowPerk=True\n",
+			set:      func(file *KFPIniFile) bool { return file.SetShowPerksEnabled(true) },
+			wantOk:   true,
+			expected: "[KFPatcher.Settings]\nbShowPerk=True\n",
+		},
+		{
+			name:     "invalid value is never replaced",
+			content:  "[KFPatcher.Settings]\nbShowPerk=maybe\n",
+			set:      func(file *KFPIniFile) bool { return file.SetZEDTimeEnabled(false) },
+			wantOk:   false,
+			expected: "[KFPatcher.Settings]\nbShowPerk=maybe\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, filePath := loadTestKFPIniFile(t, tt.content)
+			if ok := tt.set(file); ok != tt.wantOk {
+				t.Fatalf("setter returned %v, expected %v", ok, tt.wantOk)
+			}
+			if err := file.Save(filePath); err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != tt.expected {
+				t.Errorf("saved:\n%q\nexpected:\n%q", actual, tt.expected)
+			}
+		})
+	}
+}
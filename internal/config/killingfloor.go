@@ -74,10 +74,14 @@ const (
 )
 
 func NewKFIniFile(filePath string) (ServerIniFile, error) {
+	return newKFIniFile(filePath, "KFmod.KFGameType")
+}
+
+func newKFIniFile(filePath string, gameMode string) (ServerIniFile, error) {
 	iFile := &KFIniFile{
 		GenericIniFile: ini.NewGenericIniFile("KFIniFile"),
 		filePath:       filePath,
-		gameMode:       "KFmod.KFGameType",
+		gameMode:       gameMode,
 	}
 	if err := iFile.Load(filePath); err != nil {
 		return nil, err
@@ -182,7 +186,7 @@ func (kf *KFIniFile) IsWeaponThrowingEnabled() bool {
 }
 
 func (kf *KFIniFile) IsWeaponShakeEffectEnabled() bool {
-	return kf.GetKeyBool(kfSectionGameInfo, kfKeyWeaponShakeEffect, settings.DefaultDisableWeaponShake)
+	return kf.GetKeyBool(kfSectionGameInfo, kfKeyWeaponShakeEffect, !settings.DefaultDisableWeaponShake)
 }
 
 func (kf *KFIniFile) IsThirdPersonEnabled() bool {
@@ -373,6 +377,10 @@ func (kf *KFIniFile) SetServerMutators(mutators []string) error {
 	return nil
 }
 
+func (kf *KFIniFile) GetMaplist(sectionName string) []string {
+	return kf.GetKeys(sectionName, kfKeyMaps)
+}
+
 func (kf *KFIniFile) ClearMaplist(sectionName string) error {
 	if section := kf.GetSection(sectionName); section != nil {
 		section.DeleteKey(kfKeyMaps)
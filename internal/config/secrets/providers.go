@@ -0,0 +1,144 @@
+package secrets
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirProvider reads each secret from a file of the same name in a directory,
+// such as Docker secrets or a Kubernetes secret volume.
+type DirProvider struct {
+	Dir string
+}
+
+func (p *DirProvider) Name() string { return "dir" }
+
+func (p *DirProvider) Lookup(secretName string) (string, error) {
+	if p.Dir == "" {
+		return "", ErrNotFound
+	}
+	return readSecretFile(filepath.Join(p.Dir, secretName))
+}
+
+// EnvProvider reads each secret from the environment variable named after it in uppercase,
+// or from the file set in the same variable suffixed with '_FILE'.
+type EnvProvider struct{}
+
+func (p *EnvProvider) Name() string { return "env" }
+
+func (p *EnvProvider) Lookup(secretName string) (string, error) {
+	envName := strings.ToUpper(secretName)
+	value, hasValue := os.LookupEnv(envName)
+	filePath := os.Getenv(envName + "_FILE")
+
+	switch {
+	case hasValue && filePath != "":
+		return "", fmt.Errorf("both %s and %s_FILE are set, only one of them is allowed", envName, envName)
+	case hasValue:
+		return value, nil
+	case filePath != "":
+		return readSecretFile(filePath)
+	}
+	return "", ErrNotFound
+}
+
+// FileProvider reads the secrets from a single file of 'name=value' lines.
+// Empty lines and lines starting with '#' are ignored.
+type FileProvider struct {
+	Path string
+
+	secrets map[string]string
+}
+
+func (p *FileProvider) Name() string { return "file" }
+
+func (p *FileProvider) Lookup(secretName string) (string, error) {
+	if p.Path == "" {
+		return "", ErrNotFound
+	}
+
+	if p.secrets == nil {
+		secrets, err := parseSecretsFile(p.Path)
+		if err != nil {
+			return "", err
+		}
+		p.secrets = secrets
+	}
+
+	value, ok := p.secrets[strings.ToLower(secretName)]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// StoreProvider reads the secrets from a local encrypted store.
+type StoreProvider struct {
+	Store *Store
+
+	secrets map[string]string
+}
+
+func (p *StoreProvider) Name() string { return "store" }
+
+func (p *StoreProvider) Lookup(secretName string) (string, error) {
+	if p.Store == nil {
+		return "", ErrNotFound
+	}
+
+	if p.secrets == nil {
+		secrets, err := p.Store.Load()
+		if err != nil {
+			return "", err
+		}
+		p.secrets = secrets
+	}
+
+	value, ok := p.secrets[strings.ToLower(secretName)]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func readSecretFile(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func parseSecretsFile(filePath string) (map[string]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	secrets := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected 'name=value'", filePath, lineNum)
+		}
+		secrets[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return secrets, scanner.Err()
+}
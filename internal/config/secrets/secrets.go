@@ -1,17 +1,93 @@
 package secrets
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
 )
 
-func Read(secretName string) (string, error) {
-	secretPath := filepath.Join("/run/secrets", secretName)
-	data, err := os.ReadFile(secretPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read secret '%s': %w", secretName, err)
+const DefaultDir = "/run/secrets"
+
+// DefaultOrder is the lookup order used for every secret without its own order
+const DefaultOrder = "dir,file,store,env"
+
+// ErrNotFound is returned by a provider that doesn't hold the requested secret
+var ErrNotFound = errors.New("secret not found")
+
+// Provider is a secret backend.
+type Provider interface {
+	Name() string
+	Lookup(secretName string) (string, error)
+}
+
+// Resolver looks up secrets through a list of providers, in a configurable order.
+type Resolver struct {
+	providers map[string]Provider
+	order     []string
+	overrides map[string][]string
+}
+
+// NewResolver returns a resolver using the given providers.
+// The order is a comma-separated list of provider names, optionally followed by
+// per-secret orders separated by semicolons, e.g. "dir,env;steamacc_password=store,dir".
+func NewResolver(order string, providers ...Provider) (*Resolver, error) {
+	r := &Resolver{
+		providers: make(map[string]Provider),
+		overrides: make(map[string][]string),
+	}
+	for _, p := range providers {
+		r.providers[p.Name()] = p
+	}
+
+	if strings.TrimSpace(order) == "" {
+		order = DefaultOrder
+	}
+
+	for _, entry := range strings.Split(order, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		secretName, names, isOverride := strings.Cut(entry, "=")
+		if !isOverride {
+			names = secretName
+		}
+
+		var list []string
+		for _, name := range strings.Split(names, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, ok := r.providers[name]; !ok {
+				return nil, fmt.Errorf("unknown secret provider '%s'", name)
+			}
+			list = append(list, name)
+		}
+
+		if isOverride {
+			r.overrides[strings.ToLower(strings.TrimSpace(secretName))] = list
+		} else {
+			r.order = list
+		}
+	}
+	return r, nil
+}
+
+// Read returns the value of a secret and the name of the provider it was found in.
+func (r *Resolver) Read(secretName string) (string, string, error) {
+	order, ok := r.overrides[strings.ToLower(secretName)]
+	if !ok {
+		order = r.order
+	}
+
+	for _, name := range order {
+		value, err := r.providers[name].Lookup(secretName)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read secret '%s' from %s: %w", secretName, name, err)
+		}
+		return value, name, nil
 	}
-	return strings.TrimSpace(string(data)), nil
+	return "", "", fmt.Errorf("%w: %s (looked up in: %s)", ErrNotFound, secretName, strings.Join(order, ", "))
 }
@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const StoreKeySize = 32
+
+// Store is a local file holding secrets encrypted with AES-256-GCM.
+type Store struct {
+	path string
+	aead cipher.AEAD
+}
+
+func NewStore(filePath string, key []byte) (*Store, error) {
+	if len(key) != StoreKeySize {
+		return nil, fmt.Errorf("invalid store key: expected %d bytes, got %d", StoreKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: filePath, aead: aead}, nil
+}
+
+// ReadStoreKey reads a base64-encoded store key from a file.
+func ReadStoreKey(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid store key: %w", err)
+	}
+	return key, nil
+}
+
+// Load decrypts the store. A missing store is empty.
+func (s *Store) Load() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("invalid store '%s': file is truncated", s.path)
+	}
+
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt the store '%s': wrong key or corrupted file", s.path)
+	}
+
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid store '%s': %w", s.path, err)
+	}
+	return secrets, nil
+}
+
+// Save encrypts the secrets and replaces the store.
+func (s *Store) Save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, nil)
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+// OpenStore returns the store at filePath, decrypted using the key read from keyFile.
+func OpenStore(filePath string, keyFile string) (*Store, error) {
+	if keyFile == "" {
+		return nil, fmt.Errorf("no key file was given for the store '%s'", filePath)
+	}
+
+	key, err := ReadStoreKey(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the store key '%s': %w", keyFile, err)
+	}
+	return NewStore(filePath, key)
+}
@@ -1,9 +1,19 @@
 package config
 
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/K4rian/kfdsl/internal/config/ini"
+)
+
 type ServerIniFile interface {
 	FilePath() string
 	Load(filePath string) error
 	Save(filePath string) error
+	ApplyOverlayDir(dir string) ([]string, error)
+	MergeBase() ini.MergeBase
+	SetMergeBase(base ini.MergeBase)
 
 	GetServerName() string
 	GetShortName() string
@@ -65,6 +75,43 @@ type ServerIniFile interface {
 	ClearServerMutators() error
 	SetServerMutators(mutators []string) error
 
+	GetMaplist(sectionName string) []string
 	ClearMaplist(sectionName string) error
 	SetMaplist(sectionName string, maps []string) error
 }
+
+const (
+	IniTypeKillingFloor = "killingfloor"
+	IniTypeToyGame      = "toygame"
+)
+
+// NewServerIniFile loads a server configuration file of the given type.
+// The game section holds the game type settings, such as the game length.
+func NewServerIniFile(iniType string, filePath string, gameSection string) (ServerIniFile, error) {
+	switch iniType {
+	case IniTypeKillingFloor:
+		if gameSection == "" {
+			return NewKFIniFile(filePath)
+		}
+		return newKFIniFile(filePath, gameSection)
+	case IniTypeToyGame:
+		return NewKFTGIniFile(filePath)
+	}
+	return nil, fmt.Errorf("unknown server configuration file type: %s", iniType)
+}
+
+// PropertyGetter returns the Get<Property> or Is<Property> method of an ini file, if any.
+// The properties are the ones named by the 'iniprop' tag of the settings.
+func PropertyGetter(iniFile any, property string) reflect.Value {
+	fileVal := reflect.ValueOf(iniFile)
+	getter := fileVal.MethodByName("Get" + property)
+	if !getter.IsValid() {
+		getter = fileVal.MethodByName("Is" + property)
+	}
+	return getter
+}
+
+// PropertySetter returns the Set<Property> method of an ini file, if any.
+func PropertySetter(iniFile any, property string) reflect.Value {
+	return reflect.ValueOf(iniFile).MethodByName("Set" + property)
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+// newTestSettings returns settings with every argument set to its zero value.
+func newTestSettings() *settings.KFDSLSettings {
+	sett := &settings.KFDSLSettings{}
+	val := reflect.ValueOf(sett).Elem()
+	for i := 0; i < val.NumField(); i++ {
+		if field := val.Field(i); field.Kind() == reflect.Ptr {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+	}
+	return sett
+}
+
+func TestIniMappingsResolve(t *testing.T) {
+	sett := newTestSettings()
+
+	tests := []struct {
+		file     string
+		iniFiles []any
+	}{
+		{"server", []any{(*KFIniFile)(nil), (*KFTGIniFile)(nil)}},
+		{"kfpatcher", []any{(*KFPIniFile)(nil)}},
+	}
+
+	for _, tt := range tests {
+		mappings := sett.IniMappings(tt.file)
+		if len(mappings) == 0 {
+			t.Errorf("no %s mappings found", tt.file)
+		}
+
+		for _, iniFile := range tt.iniFiles {
+			for _, mapping := range mappings {
+				name := reflect.TypeOf(iniFile).Elem().Name() + "/" + mapping.Property
+				t.Run(name, func(t *testing.T) {
+					getter := PropertyGetter(iniFile, mapping.Property)
+					if !getter.IsValid() {
+						t.Fatalf("no getter found for '%s'", mapping.Property)
+					}
+					setter := PropertySetter(iniFile, mapping.Property)
+					if !setter.IsValid() {
+						t.Fatalf("no setter found for '%s'", mapping.Property)
+					}
+
+					valueType := reflect.TypeOf(mapping.Value)
+					if setter.Type().NumIn() != 1 || !valueType.AssignableTo(setter.Type().In(0)) {
+						t.Errorf("Set%s doesn't accept a %s value", mapping.Property, valueType)
+					}
+					if getter.Type().NumOut() != 1 || getter.Type().Out(0) != valueType {
+						t.Errorf("the getter of '%s' doesn't return a %s value", mapping.Property, valueType)
+					}
+				})
+			}
+		}
+	}
+}
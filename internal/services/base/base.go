@@ -3,6 +3,7 @@ package base
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -33,6 +34,15 @@ type BaseService struct {
 	done        chan struct{}
 	stopping    bool
 	execErr     error
+	stateFile   string
+	onRestart   func()
+}
+
+// ServiceState describes a running service process. It is written to the state file, if any.
+type ServiceState struct {
+	Name      string    `json:"name"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"startedAt"`
 }
 
 func NewBaseService(name string, rootDir string, ctx context.Context) *BaseService {
@@ -53,11 +63,39 @@ func (bs *BaseService) RootDirectory() string {
 	return bs.rootDir
 }
 
+// SetStateFile sets the file written while the process is running, so other
+// processes can find out about it. An empty path disables the state file.
+func (bs *BaseService) SetStateFile(filePath string) {
+	bs.stateFile = filePath
+}
+
+// SetRestartHook sets a function called before each automatic restart of the process.
+func (bs *BaseService) SetRestartHook(hook func()) {
+	bs.onRestart = hook
+}
+
 // Start initiates the service's process and manages the start/stop lifecycle.
 func (bs *BaseService) Start(args []string, autoRestart bool) error {
 	bs.mu.Lock()
 	defer bs.mu.Unlock()
 
+	if err := bs.start(args, autoRestart); err != nil {
+		return err
+	}
+
+	// Goroutine to handle the process auto-restart.
+	// It restarts the process itself, so only one is running at a time
+	if bs.autoRestart {
+		go bs.monitorAutoRestart()
+	}
+
+	// Goroutine to monitor the cancellation context
+	go bs.monitorCancellation()
+	return nil
+}
+
+// start starts the process. The caller must hold the lock.
+func (bs *BaseService) start(args []string, autoRestart bool) error {
 	if bs.IsRunning() {
 		return fmt.Errorf("already running")
 	}
@@ -90,14 +128,16 @@ func (bs *BaseService) Start(args []string, autoRestart bool) error {
 	}
 	bs.ptmx = ptmx
 
+	// Write the state file
+	if bs.stateFile != "" {
+		if err := bs.writeState(cmd.Process.Pid); err != nil {
+			bs.logger.Warn("Failed to write the state file", "file", bs.stateFile, "error", err)
+		}
+	}
+
 	// Create a done channel to signal when the process is finished
 	bs.done = make(chan struct{})
 
-	// Goroutine to handle the process auto-restart
-	if bs.autoRestart {
-		go bs.monitorAutoRestart()
-	}
-
 	// Goroutine for real-time log capture and wait for process exit
 	go func() {
 		defer func() {
@@ -107,6 +147,9 @@ func (bs *BaseService) Start(args []string, autoRestart bool) error {
 				bs.ptmx = nil
 			}
 			bs.cmd = nil
+			if bs.stateFile != "" {
+				os.Remove(bs.stateFile)
+			}
 			bs.mu.Unlock()
 			close(bs.done)
 		}()
@@ -129,9 +172,6 @@ func (bs *BaseService) Start(args []string, autoRestart bool) error {
 			}
 		}
 	}()
-
-	// Goroutine to monitor the cancellation context
-	go bs.monitorCancellation()
 	return nil
 }
 
@@ -140,14 +180,14 @@ func (bs *BaseService) Stop() error {
 	bs.mu.Lock()
 	defer bs.mu.Unlock()
 
+	// Prevent auto-restart, including a pending one
+	bs.stopping = true
+
 	// The process is already stopped or never started
 	if bs.cmd == nil {
 		return nil
 	}
 
-	// Prevent auto-restart
-	bs.stopping = true
-
 	// Send CTRL+C to gracefully terminate the service
 	if bs.ptmx != nil {
 		bs.logger.Info("Attempting to send SIGINT...")
@@ -201,6 +241,33 @@ func (bs *BaseService) IsAvailable() bool {
 	return false
 }
 
+// writeState writes the state of the running process to the state file.
+func (bs *BaseService) writeState(pid int) error {
+	data, err := json.Marshal(ServiceState{
+		Name:      bs.name,
+		PID:       pid,
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(bs.stateFile, data, 0644)
+}
+
+// ReadState reads a state file written by a running service.
+func ReadState(filePath string) (*ServiceState, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var state ServiceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid state file '%s': %w", filePath, err)
+	}
+	return &state, nil
+}
+
 // monitorAutoRestart keeps an eye on the process and restarts it.
 func (bs *BaseService) monitorAutoRestart() {
 	for {
@@ -215,7 +282,21 @@ func (bs *BaseService) monitorAutoRestart() {
 
 			if bs.ctx.Err() == nil {
 				time.Sleep(2 * time.Second)
-				bs.Start(bs.args, bs.autoRestart)
+				if bs.onRestart != nil {
+					bs.onRestart()
+				}
+
+				bs.mu.Lock()
+				if bs.stopping {
+					bs.mu.Unlock()
+					return
+				}
+				err := bs.start(bs.args, bs.autoRestart)
+				bs.mu.Unlock()
+				if err != nil {
+					bs.logger.Error(fmt.Sprintf("Failed to restart %s", bs.name), "error", err)
+					return
+				}
 			}
 		} else {
 			break
@@ -0,0 +1,84 @@
+package kfserver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultSpecimenCalendar matches the official seasonal events
+const DefaultSpecimenCalendar = "06-01..08-31=ET_SummerSideshow,10-01..10-31=ET_HillbillyHorror,12-01..12-31=ET_TwistedChristmas"
+
+// Specimen types known by the game
+var specimenTypes = []string{"ET_None", "ET_SummerSideshow", "ET_HillbillyHorror", "ET_TwistedChristmas"}
+
+// SpecimenEvent is a yearly date range during which a specimen type is used.
+// Ranges ending before they start wrap around the new year.
+type SpecimenEvent struct {
+	Start        MonthDay
+	End          MonthDay
+	SpecimenType string
+}
+
+type MonthDay struct {
+	Month time.Month
+	Day   int
+}
+
+func (md MonthDay) before(other MonthDay) bool {
+	return md.Month < other.Month || (md.Month == other.Month && md.Day < other.Day)
+}
+
+func (e SpecimenEvent) Contains(t time.Time) bool {
+	md := MonthDay{Month: t.Month(), Day: t.Day()}
+	if e.End.before(e.Start) {
+		return !md.before(e.Start) || !e.End.before(md)
+	}
+	return !md.before(e.Start) && !e.End.before(md)
+}
+
+// ParseSpecimenCalendar parses a comma-separated list of 'MM-DD..MM-DD=ET_Type' events.
+func ParseSpecimenCalendar(calendar string) ([]SpecimenEvent, error) {
+	var events []SpecimenEvent
+
+	for _, entry := range strings.Split(calendar, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		dates, specimenType, ok := strings.Cut(entry, "=")
+		start, end, hasRange := strings.Cut(dates, "..")
+		if !ok || !hasRange {
+			return nil, fmt.Errorf("invalid calendar event '%s': expected 'MM-DD..MM-DD=ET_Type'", entry)
+		}
+
+		specimenType = strings.TrimSpace(specimenType)
+		idx := slices.IndexFunc(specimenTypes, func(t string) bool { return strings.EqualFold(t, specimenType) })
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid calendar event '%s': unknown specimen type '%s', expected one of %s",
+				entry, specimenType, strings.Join(specimenTypes, ", "))
+		}
+
+		event := SpecimenEvent{SpecimenType: specimenTypes[idx]}
+		var err error
+		if event.Start, err = parseMonthDay(start); err != nil {
+			return nil, fmt.Errorf("invalid calendar event '%s': %w", entry, err)
+		}
+		if event.End, err = parseMonthDay(end); err != nil {
+			return nil, fmt.Errorf("invalid calendar event '%s': %w", entry, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func parseMonthDay(s string) (MonthDay, error) {
+	// Use a leap year so that 02-29 is accepted
+	t, err := time.Parse("2006-01-02", "2000-"+strings.TrimSpace(s))
+	if err != nil {
+		return MonthDay{}, fmt.Errorf("invalid date '%s': expected 'MM-DD'", s)
+	}
+	return MonthDay{Month: t.Month(), Day: t.Day()}, nil
+}
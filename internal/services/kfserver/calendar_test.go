@@ -0,0 +1,121 @@
+package kfserver
+
+import (
+	"testing"
+	"time"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2025, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func TestSpecimenEventContains(t *testing.T) {
+	summer := SpecimenEvent{Start: MonthDay{time.June, 1}, End: MonthDay{time.August, 31}}
+	newYear := SpecimenEvent{Start: MonthDay{time.December, 20}, End: MonthDay{time.January, 5}}
+
+	tests := []struct {
+		name     string
+		event    SpecimenEvent
+		date     time.Time
+		expected bool
+	}{
+		{"before the start", summer, date(time.May, 31), false},
+		{"first day", summer, date(time.June, 1), true},
+		{"within", summer, date(time.July, 15), true},
+		{"last day", summer, date(time.August, 31), true},
+		{"after the end", summer, date(time.September, 1), false},
+		{"new year, before the start", newYear, date(time.December, 19), false},
+		{"new year, first day", newYear, date(time.December, 20), true},
+		{"new year, last day of the year", newYear, date(time.December, 31), true},
+		{"new year, first day of the year", newYear, date(time.January, 1), true},
+		{"new year, last day", newYear, date(time.January, 5), true},
+		{"new year, after the end", newYear, date(time.January, 6), false},
+		{"new year, mid-year", newYear, date(time.July, 1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.event.Contains(tt.date); actual != tt.expected {
+				t.Errorf("Contains(%s) = %v, expected %v", tt.date.Format("01-02"), actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultSpecimenCalendar(t *testing.T) {
+	calendar, err := ParseSpecimenCalendar(DefaultSpecimenCalendar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		date     time.Time
+		expected string
+	}{
+		{date(time.January, 1), "ET_None"},
+		{date(time.May, 31), "ET_None"},
+		{date(time.June, 1), "ET_SummerSideshow"},
+		{date(time.August, 31), "ET_SummerSideshow"},
+		{date(time.September, 30), "ET_None"},
+		{date(time.October, 31), "ET_HillbillyHorror"},
+		{date(time.November, 1), "ET_None"},
+		{date(time.December, 25), "ET_TwistedChristmas"},
+	}
+
+	for _, tt := range tests {
+		if actual := GetSeasonalSpecimenType(calendar, tt.date); actual != tt.expected {
+			t.Errorf("GetSeasonalSpecimenType(%s) = %s, expected %s", tt.date.Format("01-02"), actual, tt.expected)
+		}
+	}
+}
+
+func TestParseSpecimenCalendar(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar string
+		expected []SpecimenEvent
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			calendar: "",
+		},
+		{
+			name:     "case-insensitive specimen type",
+			calendar: " 12-20..01-05 = et_twistedchristmas ",
+			expected: []SpecimenEvent{{Start: MonthDay{time.December, 20}, End: MonthDay{time.January, 5}, SpecimenType: "ET_TwistedChristmas"}},
+		},
+		{
+			name:     "unknown specimen type",
+			calendar: "06-01..08-31=ET_SumerSideshow",
+			wantErr:  true,
+		},
+		{
+			name:     "missing range",
+			calendar: "06-01=ET_SummerSideshow",
+			wantErr:  true,
+		},
+		{
+			name:     "invalid date",
+			calendar: "02-30..03-01=ET_None",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := ParseSpecimenCalendar(tt.calendar)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSpecimenCalendar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("ParseSpecimenCalendar() = %+v, expected %+v", actual, tt.expected)
+			}
+			for i := range actual {
+				if actual[i] != tt.expected[i] {
+					t.Errorf("ParseSpecimenCalendar() = %+v, expected %+v", actual, tt.expected)
+				}
+			}
+		})
+	}
+}
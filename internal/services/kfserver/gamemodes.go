@@ -0,0 +1,184 @@
+package kfserver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/K4rian/kfdsl/internal/config"
+)
+
+// GameMode describes a game type the server can run.
+type GameMode struct {
+	Name           string   `mapstructure:"name" json:"name"`                      // Name used by the gamemode flag
+	Class          string   `mapstructure:"class" json:"class"`                    // Game info class, e.g. 'KFmod.KFGameType'
+	MapPrefixes    []string `mapstructure:"map-prefixes" json:"mapPrefixes"`       // Prefixes of the maps the game mode can run
+	MaplistSection string   `mapstructure:"maplist-section" json:"maplistSection"` // Ini section holding the game mode maplist
+	IniTemplate    string   `mapstructure:"ini-template" json:"iniTemplate"`       // Default configuration file, embedded or absolute path
+	IniType        string   `mapstructure:"ini-type" json:"iniType"`               // Configuration file layout (killingfloor or toygame)
+	GameSection    string   `mapstructure:"game-section" json:"gameSection"`       // Ini section holding the game settings, defaults to the class
+}
+
+var (
+	gameModesMu sync.RWMutex
+	gameModes   = []GameMode{
+		{
+			Name:           "survival",
+			Class:          "KFmod.KFGameType",
+			MapPrefixes:    []string{"KF-"},
+			MaplistSection: "KFmod.KFMaplist",
+			IniTemplate:    "KillingFloor.ini",
+			IniType:        config.IniTypeKillingFloor,
+			GameSection:    "KFmod.KFGameType",
+		},
+		{
+			Name:           "objective",
+			Class:          "KFStoryGame.KFstoryGameInfo",
+			MapPrefixes:    []string{"KFO-"},
+			MaplistSection: "KFStoryGame.KFOMapList",
+			IniTemplate:    "KillingFloor.ini",
+			IniType:        config.IniTypeKillingFloor,
+			GameSection:    "KFmod.KFGameType",
+		},
+		{
+			Name:           "toymaster",
+			Class:          "KFCharPuppets.TOYGameInfo",
+			MapPrefixes:    []string{"TOY-"},
+			MaplistSection: "KFCharPuppets.TOYMapList",
+			IniTemplate:    "ToyGame.ini",
+			IniType:        config.IniTypeToyGame,
+			GameSection:    "KFCharPuppets.TOYGameInfo",
+		},
+	}
+)
+
+// NormalizeGameMode checks a custom game mode and fills in its default values.
+func NormalizeGameMode(mode GameMode) (GameMode, error) {
+	mode.Name = strings.ToLower(strings.TrimSpace(mode.Name))
+	if mode.Name == "" {
+		return GameMode{}, fmt.Errorf("game mode without a name")
+	}
+	if mode.Class == "" {
+		return GameMode{}, fmt.Errorf("game mode '%s': undefined class", mode.Name)
+	}
+	if len(mode.MapPrefixes) == 0 {
+		return GameMode{}, fmt.Errorf("game mode '%s': undefined map prefixes", mode.Name)
+	}
+	if mode.MaplistSection == "" {
+		return GameMode{}, fmt.Errorf("game mode '%s': undefined maplist section", mode.Name)
+	}
+
+	switch mode.IniType = strings.ToLower(mode.IniType); mode.IniType {
+	case "":
+		mode.IniType = config.IniTypeKillingFloor
+	case config.IniTypeKillingFloor, config.IniTypeToyGame:
+	default:
+		return GameMode{}, fmt.Errorf("game mode '%s': invalid ini type '%s', expected %s or %s", mode.Name, mode.IniType, config.IniTypeKillingFloor, config.IniTypeToyGame)
+	}
+
+	if mode.IniTemplate == "" {
+		mode.IniTemplate = "KillingFloor.ini"
+		if mode.IniType == config.IniTypeToyGame {
+			mode.IniTemplate = "ToyGame.ini"
+		}
+	}
+	if mode.GameSection == "" {
+		mode.GameSection = mode.Class
+	}
+	return mode, nil
+}
+
+// RegisterGameMode adds a custom game mode, or replaces the one with the same name.
+func RegisterGameMode(mode GameMode) error {
+	mode, err := NormalizeGameMode(mode)
+	if err != nil {
+		return err
+	}
+
+	gameModesMu.Lock()
+	defer gameModesMu.Unlock()
+
+	idx := slices.IndexFunc(gameModes, func(m GameMode) bool { return m.Name == mode.Name })
+	if idx >= 0 {
+		gameModes[idx] = mode
+	} else {
+		gameModes = append(gameModes, mode)
+	}
+	return nil
+}
+
+// GetGameMode returns the game mode matching a name or a game info class.
+func GetGameMode(nameOrClass string) (GameMode, bool) {
+	gameModesMu.RLock()
+	defer gameModesMu.RUnlock()
+
+	for _, mode := range gameModes {
+		if strings.EqualFold(mode.Name, nameOrClass) || strings.EqualFold(mode.Class, nameOrClass) {
+			return mode, true
+		}
+	}
+	return GameMode{}, false
+}
+
+// GetGameModeOrDefault returns the game mode matching a name or a game info class.
+// Unregistered custom game modes use the Killing Floor configuration file.
+func GetGameModeOrDefault(nameOrClass string) GameMode {
+	mode, ok := GetGameMode(nameOrClass)
+	if !ok {
+		mode = GameMode{
+			IniTemplate: "KillingFloor.ini",
+			IniType:     config.IniTypeKillingFloor,
+		}
+	}
+	return mode
+}
+
+// LookupGameMode is like GetGameMode, but also searches game modes not registered yet.
+// They take precedence over the registered ones with the same name.
+func LookupGameMode(nameOrClass string, pending []GameMode) (GameMode, bool) {
+	for _, mode := range pending {
+		if strings.EqualFold(mode.Name, nameOrClass) || strings.EqualFold(mode.Class, nameOrClass) {
+			return mode, true
+		}
+	}
+
+	mode, ok := GetGameMode(nameOrClass)
+	if ok && slices.ContainsFunc(pending, func(m GameMode) bool { return m.Name == mode.Name }) {
+		return GameMode{}, false
+	}
+	return mode, ok
+}
+
+func GetGameModes() []string {
+	gameModesMu.RLock()
+	defer gameModesMu.RUnlock()
+
+	var ret []string
+	for _, mode := range gameModes {
+		ret = append(ret, mode.Name)
+	}
+	return ret
+}
+
+func GetGameModeMapPrefixes(gamemode string) []string {
+	mode, _ := GetGameMode(gamemode)
+	return mode.MapPrefixes
+}
+
+func GetGameModeMaplistName(gamemode string) string {
+	mode, _ := GetGameMode(gamemode)
+	return mode.MaplistSection
+}
+
+// HasGameModeMapPrefix reports whether a map can be run by a game mode.
+// Maps of unknown game modes are always accepted.
+func HasGameModeMapPrefix(gamemode string, mapName string) bool {
+	mode, _ := GetGameMode(gamemode)
+	return mode.HasMapPrefix(mapName)
+}
+
+// HasMapPrefix reports whether a map can be run by the game mode.
+func (m GameMode) HasMapPrefix(mapName string) bool {
+	return hasAnyPrefix(mapName, m.MapPrefixes)
+}
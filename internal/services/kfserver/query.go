@@ -0,0 +1,204 @@
+package kfserver
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// Unreal Engine 2 server info request, answered by the server on its query port (game port + 1)
+var ue2InfoRequest = []byte{0x79, 0x00, 0x00, 0x00, ue2QueryInfo}
+
+// Unreal Engine 2 query types
+const (
+	ue2QueryInfo byte = 0x00
+)
+
+var errTruncatedResponse = errors.New("truncated response")
+
+// ServerInfo holds the server info query response.
+type ServerInfo struct {
+	Name       string
+	Map        string
+	GameType   string
+	Players    int
+	MaxPlayers int
+}
+
+// QueryServer sends a server info request to the server query port and
+// returns an error if no valid response is received before the context expires.
+func QueryServer(ctx context.Context, address string) error {
+	_, err := QueryServerInfo(ctx, address)
+	return err
+}
+
+// QueryPlayers returns the number of players connected to the server,
+// read from its server info response.
+func QueryPlayers(ctx context.Context, address string) (int, error) {
+	info, err := QueryServerInfo(ctx, address)
+	if err != nil {
+		return 0, err
+	}
+	return info.Players, nil
+}
+
+// QueryServerInfo sends a server info request to the server query port and returns its response.
+func QueryServerInfo(ctx context.Context, address string) (*ServerInfo, error) {
+	conn, err := dialQuery(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := exchangeQuery(conn, address, ue2InfoRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := parseServerInfo(resp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response from %s: %w", address, err)
+	}
+	return info, nil
+}
+
+// parseServerInfo parses a server info response.
+// It starts with a 4 bytes header and the query type, followed by the server ID, IP,
+// game port and query port, then the server name, map, game type, players and max players.
+func parseServerInfo(data []byte) (*ServerInfo, error) {
+	r := &ue2Reader{data: data}
+	r.skip(4)
+	if queryType := r.byte(); r.err == nil && queryType != ue2QueryInfo {
+		return nil, fmt.Errorf("unexpected query type %d", queryType)
+	}
+
+	r.int32()  // Server ID
+	r.string() // Server IP
+	r.int32()  // Game port
+	r.int32()  // Query port
+
+	info := &ServerInfo{
+		Name:       r.string(),
+		Map:        r.string(),
+		GameType:   r.string(),
+		Players:    int(r.int32()),
+		MaxPlayers: int(r.int32()),
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return info, nil
+}
+
+// ue2Reader reads the little-endian values of an Unreal Engine 2 query response.
+// The first error is kept and stops any further read.
+type ue2Reader struct {
+	data []byte
+	err  error
+}
+
+func (r *ue2Reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errTruncatedResponse
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *ue2Reader) skip(n int) {
+	r.next(n)
+}
+
+func (r *ue2Reader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *ue2Reader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.LittleEndian.Uint32(b))
+	}
+	return 0
+}
+
+// string reads a length-prefixed, null-terminated string.
+// A length with the high bit set flags an UCS-2 string of (length & 0x7F) characters,
+// otherwise the string is Latin-1. Color codes (0x1B followed by 3 bytes) are dropped.
+func (r *ue2Reader) string() string {
+	length := int(r.byte())
+
+	var runes []rune
+	if length&0x80 != 0 {
+		// Some servers insert an extra 0x01 byte after the length of UCS-2 strings
+		if len(r.data) > 0 && r.data[0] == 0x01 {
+			r.skip(1)
+		}
+
+		b := r.next((length & 0x7F) * 2)
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(b[i*2:])
+		}
+		runes = utf16.Decode(units)
+	} else {
+		for _, c := range r.next(length) {
+			runes = append(runes, rune(c))
+		}
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case 0x00:
+			return sb.String()
+		case 0x1B:
+			i += 3
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return sb.String()
+}
+
+func dialQuery(ctx context.Context, address string) (net.Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach %s: %w", address, err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func exchangeQuery(conn net.Conn, address string, request []byte) ([]byte, error) {
+	if _, err := conn.Write(request); err != nil {
+		return nil, fmt.Errorf("unable to query %s: %w", address, err)
+	}
+
+	buf := make([]byte, 1400)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("no response from %s: %w", address, err)
+	}
+	return buf[:n], nil
+}
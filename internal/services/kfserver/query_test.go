@@ -0,0 +1,115 @@
+package kfserver
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Server info response of a KF server on port 7707, hosting 3 of 6 players
+var infoResponse = []byte{
+	0x80, 0x00, 0x00, 0x00, // Header
+	0x00,                   // Query type
+	0x00, 0x00, 0x00, 0x00, // Server ID
+	0x01, 0x00, // Server IP
+	0x1B, 0x1E, 0x00, 0x00, // Game port
+	0x1C, 0x1E, 0x00, 0x00, // Query port
+	0x0A, 'K', 'F', ' ', 'S', 'e', 'r', 'v', 'e', 'r', 0x00,
+	0x0E, 'K', 'F', '-', 'B', 'i', 'o', 't', 'i', 'c', 's', 'L', 'a', 'b', 0x00,
+	0x0B, 'K', 'F', 'G', 'a', 'm', 'e', 'T', 'y', 'p', 'e', 0x00,
+	0x03, 0x00, 0x00, 0x00, // Players
+	0x06, 0x00, 0x00, 0x00, // Max players
+	0x00, 0x00, 0x00, 0x00, // Ping
+	0x00, 0x00, 0x00, 0x00, // Flags
+	0x02, '1', 0x00, // Skill
+}
+
+// withServerName returns the info response with the given encoded server name.
+func withServerName(name []byte) []byte {
+	data := bytes.Clone(infoResponse[:19])
+	data = append(data, name...)
+	return append(data, infoResponse[30:]...)
+}
+
+func TestParseServerInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *ServerInfo
+		wantErr  bool
+	}{
+		{
+			name:     "latin-1 strings",
+			data:     infoResponse,
+			expected: &ServerInfo{Name: "KF Server", Map: "KF-BioticsLab", GameType: "KFGameType", Players: 3, MaxPlayers: 6},
+		},
+		{
+			name:     "color codes",
+			data:     withServerName([]byte{0x07, 0x1B, 0xFF, 0x00, 0x00, 'K', 'F', 0x00}),
+			expected: &ServerInfo{Name: "KF", Map: "KF-BioticsLab", GameType: "KFGameType", Players: 3, MaxPlayers: 6},
+		},
+		{
+			name:     "ucs-2 string",
+			data:     withServerName([]byte{0x83, 'C', 0x00, 0xE9, 0x00, 0x00, 0x00}),
+			expected: &ServerInfo{Name: "Cé", Map: "KF-BioticsLab", GameType: "KFGameType", Players: 3, MaxPlayers: 6},
+		},
+		{
+			name:     "ucs-2 string with an extra byte",
+			data:     withServerName([]byte{0x83, 0x01, 'C', 0x00, 0xE9, 0x00, 0x00, 0x00}),
+			expected: &ServerInfo{Name: "Cé", Map: "KF-BioticsLab", GameType: "KFGameType", Players: 3, MaxPlayers: 6},
+		},
+		{
+			name:    "truncated",
+			data:    infoResponse[:60],
+			wantErr: true,
+		},
+		{
+			name:    "other query type",
+			data:    append([]byte{0x80, 0x00, 0x00, 0x00, 0x02}, infoResponse[5:]...),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseServerInfo(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseServerInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("parseServerInfo() = %+v, expected %+v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQueryPlayers(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		buf := make([]byte, 64)
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil || !bytes.Equal(buf[:n], ue2InfoRequest) {
+			return
+		}
+		conn.WriteTo(infoResponse, addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	players, err := QueryPlayers(ctx, conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if players != 3 {
+		t.Errorf("QueryPlayers() = %d, expected 3", players)
+	}
+}
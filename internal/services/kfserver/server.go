@@ -44,9 +44,15 @@ func NewKFServer(
 		extraArgs:      extraArgs,
 		executable:     path.Join(rootDir, "System", "ucc-bin"),
 	}
+	kfs.SetStateFile(StateFilePath(rootDir))
 	return kfs
 }
 
+// StateFilePath returns the path of the state file written while the server is running.
+func StateFilePath(rootDir string) string {
+	return path.Join(rootDir, "kfdsl.state")
+}
+
 func (s *KFServer) Start(autoRestart bool) error {
 	args := s.buildCommandLine()
 	err := s.BaseService.Start(args, autoRestart)
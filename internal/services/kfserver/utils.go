@@ -7,10 +7,29 @@ import (
 	"time"
 )
 
-func GetInstalledMaps(dir string, prefix string) ([]string, error) {
+type MapFile struct {
+	Name    string    // Map name, without extension
+	Path    string    // Full path to the map file
+	Size    int64     // File size in bytes
+	ModTime time.Time // Last modification time
+}
+
+func GetInstalledMaps(dir string, prefixes ...string) ([]string, error) {
+	mapFiles, err := GetInstalledMapFiles(dir, prefixes...)
+	if err != nil {
+		return nil, err
+	}
+
 	var filteredFiles []string
+	for _, mapFile := range mapFiles {
+		filteredFiles = append(filteredFiles, mapFile.Name)
+	}
+	return filteredFiles, nil
+}
 
-	prefix = strings.ToLower(prefix)
+// GetInstalledMapFiles returns the maps of a directory whose name starts with one of the prefixes, or every map if none is given.
+func GetInstalledMapFiles(dir string, prefixes ...string) ([]MapFile, error) {
+	var mapFiles []MapFile
 
 	files, err := os.ReadDir(dir)
 	if err != nil {
@@ -23,47 +42,46 @@ func GetInstalledMaps(dir string, prefix string) ([]string, error) {
 		}
 
 		fileName := file.Name()
-		if strings.HasPrefix(strings.ToLower(fileName), "kf-menu") || !strings.HasPrefix(strings.ToLower(fileName), prefix) {
+		if strings.HasPrefix(strings.ToLower(fileName), "kf-menu") || !hasAnyPrefix(fileName, prefixes) {
 			continue
 		}
 
 		if filepath.Ext(fileName) != ".rom" {
 			continue
 		}
-		filteredFiles = append(filteredFiles, strings.TrimSuffix(fileName, ".rom"))
-	}
-	return filteredFiles, nil
-}
 
-func GetGameModeMapPrefix(gamemode string) string {
-	modes := map[string]string{
-		"survival":  "KF-",
-		"objective": "KFO-",
-		"toymaster": "TOY-",
+		info, err := file.Info()
+		if err != nil {
+			return nil, err
+		}
+		mapFiles = append(mapFiles, MapFile{
+			Name:    strings.TrimSuffix(fileName, ".rom"),
+			Path:    filepath.Join(dir, fileName),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
 	}
-	return modes[strings.ToLower(gamemode)]
+	return mapFiles, nil
 }
 
-func GetGameModeMaplistName(gamemode string) string {
-	mlist := map[string]string{
-		"survival":  "KFmod.KFMaplist",
-		"objective": "KFStoryGame.KFOMapList",
-		"toymaster": "KFCharPuppets.TOYMapList",
+// GetSeasonalSpecimenType returns the specimen type of the first calendar event containing the given date.
+func GetSeasonalSpecimenType(calendar []SpecimenEvent, t time.Time) string {
+	for _, event := range calendar {
+		if event.Contains(t) {
+			return event.SpecimenType
+		}
 	}
-	return mlist[strings.ToLower(gamemode)]
+	return "ET_None"
 }
 
-func GetSeasonalSpecimenType() string {
-	currentMonth := time.Now().Month()
-
-	switch currentMonth {
-	case time.June, time.July, time.August:
-		return "ET_SummerSideshow"
-	case time.October:
-		return "ET_HillbillyHorror"
-	case time.December:
-		return "ET_TwistedChristmas"
-	default:
-		return "ET_None"
+func hasAnyPrefix(name string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			return true
+		}
 	}
+	return false
 }
@@ -3,65 +3,67 @@ package settings
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/K4rian/kfdsl/internal/arguments"
 	"github.com/K4rian/kfdsl/internal/log"
 )
 
 type KFDSLSettings struct {
-	ConfigFile           *arguments.Argument[string]  // Server Configuration File
-	ServerName           *arguments.Argument[string]  // Server Name
-	ShortName            *arguments.Argument[string]  // Server Alias
-	GamePort             *arguments.Argument[int]     // Port
-	WebAdminPort         *arguments.Argument[int]     // Web Admin Panel Port
-	GameSpyPort          *arguments.Argument[int]     // GameSpy Port
-	GameMode             *arguments.Argument[string]  // Game Mode to use (Survival, Objective, Toy Master or Custom)
-	StartupMap           *arguments.Argument[string]  // Starting map
-	GameDifficulty       *arguments.Argument[int]     // Game Difficulty
-	GameLength           *arguments.Argument[int]     // Game Length
-	FriendlyFire         *arguments.Argument[float64] // Friendly Fire Rate
-	MaxPlayers           *arguments.Argument[int]     // Maximum Players
-	MaxSpectators        *arguments.Argument[int]     // Maximum Spectators
-	Password             *arguments.Argument[string]  // Server Password
-	Region               *arguments.Argument[int]     // Server Region
-	AdminName            *arguments.Argument[string]  // Administrator Name
-	AdminMail            *arguments.Argument[string]  // Administrator Email address
-	AdminPassword        *arguments.Argument[string]  // Administrator Password
-	MOTD                 *arguments.Argument[string]  // Message of the Day
-	SpecimenType         *arguments.Argument[string]  // Specimen type to use
-	Mutators             *arguments.Argument[string]  // Mutators list (Command-line)
-	ServerMutators       *arguments.Argument[string]  // Mutators list (ServerActors)
-	RedirectURL          *arguments.Argument[string]  // Redirection URL (extra content)
-	Maplist              *arguments.Argument[string]  // Map list
-	EnableWebAdmin       *arguments.Argument[bool]    // Enable the Web Admin Panel
-	EnableMapVote        *arguments.Argument[bool]    // Enable Map voting
-	MapVoteRepeatLimit   *arguments.Argument[int]     // Map vote repeat limit (number of maps to be played before a map can repeat)
-	EnableAdminPause     *arguments.Argument[bool]    // Allow the administrator(s) to pause the game
-	DisableWeaponThrow   *arguments.Argument[bool]    // Prevent the weapons from being thrown on the ground
-	DisableWeaponShake   *arguments.Argument[bool]    // Prevent the weapons from shaking the screen
-	EnableThirdPerson    *arguments.Argument[bool]    // Enable third-person view (using F4)
-	EnableLowGore        *arguments.Argument[bool]    // Disable the gore system (specimens can't be dismembered)
-	Uncap                *arguments.Argument[bool]    // Uncap the framerate (must also be tweaked in the client)
-	Unsecure             *arguments.Argument[bool]    // Start the server without Valve Anti-Cheat (VAC)
-	NoSteam              *arguments.Argument[bool]    // Bypass SteamCMD and start the server right away
-	NoValidate           *arguments.Argument[bool]    // Skip server files integrity check
-	AutoRestart          *arguments.Argument[bool]    // Auto restart the server if it crashes
-	EnableMutLoader      *arguments.Argument[bool]    // Enable MutLoader (https://github.com/Bleeding-Action-Man/MutLoader)
-	EnableKFPatcher      *arguments.Argument[bool]    // Enable KFPatcher (https://github.com/InsultingPros/KFPatcher)
-	KFPHidePerks         *arguments.Argument[bool]    // KFPatcher: Hide Perks
-	KFPDisableZedTime    *arguments.Argument[bool]    // KFPatcher: Disable ZED Time
-	KFPBuyEverywhere     *arguments.Argument[bool]    // KFPatcher: Allows opening the buy menu anywhere (untested)
-	KFPEnableAllTraders  *arguments.Argument[bool]    // KFPatcher: All of the trader's spots are accessible after each wave
-	KFPAllTradersMessage *arguments.Argument[string]  // KFPatcher: All traders open message
-	KFPatcherURL         *arguments.Argument[string]  // KFPatcher: archive URL
-	KFUnflectURL         *arguments.Argument[string]  // KFPatcher: KFUnflect URL
-	LogToFile            *arguments.Argument[bool]    // Enable file logging
-	LogLevel             *arguments.Argument[string]  // Log level (info, debug, warn, error)
-	LogFile              *arguments.Argument[string]  // Log file path
-	LogFileFormat        *arguments.Argument[string]  // Log format (text or json)
-	LogMaxSize           *arguments.Argument[int]     // Max log file size (MB)
-	LogMaxBackups        *arguments.Argument[int]     // Max number of old log files to keep
-	LogMaxAge            *arguments.Argument[int]     // Max age of a log file (days)
+	ConfigFile           *arguments.Argument[string]  `flag:"config"`                                                         // Server Configuration File
+	ServerName           *arguments.Argument[string]  `flag:"servername" iniprop:"server:ServerName"`                         // Server Name
+	ShortName            *arguments.Argument[string]  `flag:"shortname" iniprop:"server:ShortName"`                           // Server Alias
+	GamePort             *arguments.Argument[int]     `flag:"port" iniprop:"server:GamePort"`                                 // Port
+	WebAdminPort         *arguments.Argument[int]     `flag:"webadminport" iniprop:"server:WebAdminPort"`                     // Web Admin Panel Port
+	GameSpyPort          *arguments.Argument[int]     `flag:"gamespyport" iniprop:"server:GameSpyPort"`                       // GameSpy Port
+	GameMode             *arguments.Argument[string]  `flag:"gamemode"`                                                       // Game Mode to use (Survival, Objective, Toy Master or Custom)
+	StartupMap           *arguments.Argument[string]  `flag:"map"`                                                            // Starting map
+	GameDifficulty       *arguments.Argument[int]     `flag:"difficulty" iniprop:"server:GameDifficulty"`                     // Game Difficulty
+	GameLength           *arguments.Argument[int]     `flag:"length" iniprop:"server:GameLength"`                             // Game Length
+	FriendlyFire         *arguments.Argument[float64] `flag:"friendlyfire" iniprop:"server:FriendlyFireRate"`                 // Friendly Fire Rate
+	MaxPlayers           *arguments.Argument[int]     `flag:"maxplayers" iniprop:"server:MaxPlayers"`                         // Maximum Players
+	MaxSpectators        *arguments.Argument[int]     `flag:"maxspectators" iniprop:"server:MaxSpectators"`                   // Maximum Spectators
+	Password             *arguments.Argument[string]  `flag:"password" iniprop:"server:Password"`                             // Server Password
+	Region               *arguments.Argument[int]     `flag:"region" iniprop:"server:Region"`                                 // Server Region
+	AdminName            *arguments.Argument[string]  `flag:"adminname" iniprop:"server:AdminName"`                           // Administrator Name
+	AdminMail            *arguments.Argument[string]  `flag:"adminmail" iniprop:"server:AdminMail"`                           // Administrator Email address
+	AdminPassword        *arguments.Argument[string]  `flag:"adminpassword" iniprop:"server:AdminPassword"`                   // Administrator Password
+	MOTD                 *arguments.Argument[string]  `flag:"motd" iniprop:"server:MOTD"`                                     // Message of the Day
+	SpecimenType         *arguments.Argument[string]  `flag:"specimentype" iniprop:"server:SpecimenType"`                     // Specimen type to use
+	SpecimenCalendar     *arguments.Argument[string]  `flag:"specimen-calendar"`                                              // Seasonal specimen types calendar
+	Mutators             *arguments.Argument[string]  `flag:"mutators"`                                                       // Mutators list (Command-line)
+	ServerMutators       *arguments.Argument[string]  `flag:"servermutators"`                                                 // Mutators list (ServerActors)
+	RedirectURL          *arguments.Argument[string]  `flag:"redirecturl" iniprop:"server:RedirectURL"`                       // Redirection URL (extra content)
+	Maplist              *arguments.Argument[string]  `flag:"maplist"`                                                        // Map list
+	EnableWebAdmin       *arguments.Argument[bool]    `flag:"webadmin" iniprop:"server:WebAdminEnabled"`                      // Enable the Web Admin Panel
+	EnableMapVote        *arguments.Argument[bool]    `flag:"mapvote" iniprop:"server:MapVoteEnabled"`                        // Enable Map voting
+	MapVoteRepeatLimit   *arguments.Argument[int]     `flag:"mapvote-repeatlimit" iniprop:"server:MapVoteRepeatLimit"`        // Map vote repeat limit (number of maps to be played before a map can repeat)
+	EnableAdminPause     *arguments.Argument[bool]    `flag:"adminpause" iniprop:"server:AdminPauseEnabled"`                  // Allow the administrator(s) to pause the game
+	DisableWeaponThrow   *arguments.Argument[bool]    `flag:"noweaponthrow" iniprop:"server:WeaponThrowingEnabled,invert"`    // Prevent the weapons from being thrown on the ground
+	DisableWeaponShake   *arguments.Argument[bool]    `flag:"noweaponshake" iniprop:"server:WeaponShakeEffectEnabled,invert"` // Prevent the weapons from shaking the screen
+	EnableThirdPerson    *arguments.Argument[bool]    `flag:"thirdperson" iniprop:"server:ThirdPersonEnabled"`                // Enable third-person view (using F4)
+	EnableLowGore        *arguments.Argument[bool]    `flag:"lowgore" iniprop:"server:LowGoreEnabled"`                        // Disable the gore system (specimens can't be dismembered)
+	Uncap                *arguments.Argument[bool]    `flag:"uncap"`                                                          // Uncap the framerate (must also be tweaked in the client)
+	Unsecure             *arguments.Argument[bool]    `flag:"unsecure"`                                                       // Start the server without Valve Anti-Cheat (VAC)
+	NoSteam              *arguments.Argument[bool]    `flag:"nosteam"`                                                        // Bypass SteamCMD and start the server right away
+	NoValidate           *arguments.Argument[bool]    `flag:"novalidate"`                                                     // Skip server files integrity check
+	AutoRestart          *arguments.Argument[bool]    `flag:"autorestart"`                                                    // Auto restart the server if it crashes
+	EnableMutLoader      *arguments.Argument[bool]    `flag:"mutloader"`                                                      // Enable MutLoader (https://github.com/Bleeding-Action-Man/MutLoader)
+	EnableKFPatcher      *arguments.Argument[bool]    `flag:"kfpatcher"`                                                      // Enable KFPatcher (https://github.com/InsultingPros/KFPatcher)
+	KFPHidePerks         *arguments.Argument[bool]    `flag:"hideperks" iniprop:"kfpatcher:ShowPerksEnabled,invert"`          // KFPatcher: Hide Perks
+	KFPDisableZedTime    *arguments.Argument[bool]    `flag:"nozedtime" iniprop:"kfpatcher:ZEDTimeEnabled,invert"`            // KFPatcher: Disable ZED Time
+	KFPBuyEverywhere     *arguments.Argument[bool]    `flag:"buyeverywhere" iniprop:"kfpatcher:BuyEverywhereEnabled"`         // KFPatcher: Allows opening the buy menu anywhere (untested)
+	KFPEnableAllTraders  *arguments.Argument[bool]    `flag:"alltraders" iniprop:"kfpatcher:AllTradersOpenEnabled"`           // KFPatcher: All of the trader's spots are accessible after each wave
+	KFPAllTradersMessage *arguments.Argument[string]  `flag:"alltraders-message" iniprop:"kfpatcher:AllTradersMessage"`       // KFPatcher: All traders open message
+	KFPatcherURL         *arguments.Argument[string]  `flag:"kfpatcher-url"`                                                  // KFPatcher: archive URL
+	KFUnflectURL         *arguments.Argument[string]  `flag:"kfunflect-url"`                                                  // KFPatcher: KFUnflect URL
+	LogToFile            *arguments.Argument[bool]    `flag:"log-to-file"`                                                    // Enable file logging
+	LogLevel             *arguments.Argument[string]  `flag:"log-level"`                                                      // Log level (info, debug, warn, error)
+	LogFile              *arguments.Argument[string]  `flag:"log-file"`                                                       // Log file path
+	LogFileFormat        *arguments.Argument[string]  `flag:"log-file-format"`                                                // Log format (text or json)
+	LogMaxSize           *arguments.Argument[int]     `flag:"log-max-size"`                                                   // Max log file size (MB)
+	LogMaxBackups        *arguments.Argument[int]     `flag:"log-max-backups"`                                                // Max number of old log files to keep
+	LogMaxAge            *arguments.Argument[int]     `flag:"log-max-age"`                                                    // Max age of a log file (days)
 	ExtraArgs            []string                     // Extra arguments passed to the server
 	SteamLogin           string                       // Steam Account Login Username
 	SteamPassword        string                       // Steam Account Login Password
@@ -87,6 +89,84 @@ func (s *KFDSLSettings) Parse() error {
 	return nil
 }
 
+// FlagArgument is a parsable setting along with the name of the flag it is read from.
+type FlagArgument struct {
+	Flag  string
+	Value any // Parsed value
+	arguments.ParsableArgument
+}
+
+// Arguments returns every registered setting, in declaration order.
+func (s *KFDSLSettings) Arguments() []FlagArgument {
+	val := reflect.ValueOf(s).Elem()
+
+	var ret []FlagArgument
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			if parsable, ok := field.Interface().(arguments.ParsableArgument); ok {
+				ret = append(ret, FlagArgument{
+					Flag:             val.Type().Field(i).Tag.Get("flag"),
+					Value:            field.MethodByName("Value").Call(nil)[0].Interface(),
+					ParsableArgument: parsable,
+				})
+			}
+		}
+	}
+	return ret
+}
+
+// IniMapping links a setting to the ini file property it is written to, as declared by its 'iniprop' tag:
+// `iniprop:"<file>:<property>[,invert]"`, where property names the ini file accessors (e.g. 'ServerName' for
+// GetServerName and SetServerName, 'WebAdminEnabled' for IsWebAdminEnabled and SetWebAdminEnabled).
+// Unlike the 'ini' tag of the ini package, it doesn't name a section and a key.
+type IniMapping struct {
+	Property    string // Ini file property
+	Name        string // Setting name
+	Value       any    // Value to write
+	IsSensitive bool   // Whether the value must not be displayed
+}
+
+// IniMappings returns the mappings of every setting written to the given ini file ("server" or "kfpatcher").
+func (s *KFDSLSettings) IniMappings(file string) []IniMapping {
+	val := reflect.ValueOf(s).Elem()
+
+	var ret []IniMapping
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		tag, ok := val.Type().Field(i).Tag.Lookup("iniprop")
+		if !ok || field.Kind() != reflect.Ptr || field.IsNil() {
+			continue
+		}
+
+		target, options, _ := strings.Cut(tag, ",")
+		tagFile, property, _ := strings.Cut(target, ":")
+		if tagFile != file {
+			continue
+		}
+
+		parsable, ok := field.Interface().(arguments.ParsableArgument)
+		if !ok {
+			continue
+		}
+
+		value := field.MethodByName("Value").Call(nil)[0].Interface()
+		if options == "invert" {
+			if b, ok := value.(bool); ok {
+				value = !b
+			}
+		}
+
+		ret = append(ret, IniMapping{
+			Property:    property,
+			Name:        parsable.Name(),
+			Value:       value,
+			IsSensitive: parsable.IsSensitive(),
+		})
+	}
+	return ret
+}
+
 func (s *KFDSLSettings) Print() {
 	val := reflect.ValueOf(s).Elem()
 
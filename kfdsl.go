@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
@@ -21,27 +22,14 @@ import (
 	"github.com/K4rian/kfdsl/internal/utils"
 )
 
-type configUpdater[T any] struct {
-	name string       // Name
-	gv   func() T     // Getter
-	sv   func(T) bool // Setter
-	nv   any          // Value to set
-}
-
-func newConfigUpdater[T any](name string, gv func() T, sv func(T) bool, nv any) configUpdater[T] {
-	return configUpdater[T]{
-		name: name,
-		gv:   gv,
-		sv:   sv,
-		nv:   nv,
-	}
-}
-
 const (
 	KF_APPID = 215360
 )
 
-func startSteamCMD(sett *settings.KFDSLSettings, ctx context.Context) error {
+// Steam libraries copied from SteamCMD into the server System directory
+var steamLibraries = []string{"steamclient.so", "libtier0_s.so", "libvstdlib_s.so"}
+
+func startSteamCMD(sett *settings.KFDSLSettings, validate bool, ctx context.Context) error {
 	rootDir := viper.GetString("steamcmd-root")
 	steamCMD := steamcmd.NewSteamCMD(rootDir, ctx)
 
@@ -68,13 +56,13 @@ func startSteamCMD(sett *settings.KFDSLSettings, ctx context.Context) error {
 		sett.SteamPassword,
 		serverInstallDir,
 		KF_APPID,
-		!sett.NoValidate.Value(),
+		validate,
 	); err != nil {
 		return err
 	}
 	log.Logger.Info("Install script was successfully written", "scriptPath", installScript)
 
-	log.Logger.Info("Starting SteamCMD...", "rootDir", steamCMD.RootDirectory(), "appInstallDir", serverInstallDir)
+	log.Logger.Info("Starting SteamCMD...", "rootDir", steamCMD.RootDirectory(), "appInstallDir", serverInstallDir, "validate", validate)
 	if err := steamCMD.RunScript(installScript); err != nil && !errors.Is(err, context.Canceled) {
 		return err
 	}
@@ -127,8 +115,13 @@ func startGameServer(sett *settings.KFDSLSettings, ctx context.Context) (*kfserv
 		return nil, fmt.Errorf("unable to locate the KF Dedicated Server files in '%s', please install using SteamCMD", gameServer.RootDirectory())
 	}
 
+	snapshot, err := loadConfigSnapshot(configSnapshotPath(rootDir), viper.GetString("drift-policy"))
+	if err != nil {
+		return nil, err
+	}
+
 	log.Logger.Info("Updating the KF Dedicated Server configuration file...", "file", configFileName)
-	if err := updateConfigFile(sett); err != nil {
+	if err := updateConfigFile(sett, filepath.Join(rootDir, "System", configFileName), snapshot); err != nil {
 		return nil, fmt.Errorf("failed to update the KF Dedicated Server configuration file %s: %w", configFileName, err)
 	}
 	log.Logger.Info("Server configuration file successfully updated", "file", configFileName)
@@ -143,12 +136,16 @@ func startGameServer(sett *settings.KFDSLSettings, ctx context.Context) (*kfserv
 
 		kfpConfigFilePath := filepath.Join(rootDir, "System", "KFPatcherSettings.ini")
 		log.Logger.Info("Updating the KFPatcher configuration file...", "file", kfpConfigFilePath)
-		if err := updateKFPatcherConfigFile(sett); err != nil {
+		if err := updateKFPatcherConfigFile(sett, kfpConfigFilePath, snapshot); err != nil {
 			return nil, fmt.Errorf("failed to update the KFPatcher configuration file %s: %w", kfpConfigFilePath, err)
 		}
 		log.Logger.Info("KFPatcher configuration file successfully updated", "file", kfpConfigFilePath)
 	}
 
+	if err := snapshot.Save(); err != nil {
+		log.Logger.Error("Unable to save the configuration snapshot", "file", configSnapshotPath(rootDir), "error", err)
+	}
+
 	log.Logger.Info("Verifying KF Dedicated Server Steam libraries for updates...")
 	updatedLibs, err := updateGameServerSteamLibs()
 	if err == nil {
@@ -163,6 +160,16 @@ func startGameServer(sett *settings.KFDSLSettings, ctx context.Context) (*kfserv
 		log.Logger.Error("Unable to update the KF Dedicated Server Steam libraries", "error", err)
 	}
 
+	// Switch the seasonal specimen type on restart, if needed
+	if strings.EqualFold(sett.SpecimenType.RawValue(), "auto") {
+		configFilePath := filepath.Join(rootDir, "System", configFileName)
+		gameServer.SetRestartHook(func() {
+			settingsMu.Lock()
+			defer settingsMu.Unlock()
+			refreshSeasonalSpecimenType(sett, configFilePath, snapshot)
+		})
+	}
+
 	log.Logger.Info("Starting the KF Dedicated Server...", "rootDir", gameServer.RootDirectory(), "autoRestart", sett.AutoRestart.Value())
 	if err := gameServer.Start(sett.AutoRestart.Value()); err != nil {
 		return nil, fmt.Errorf("failed to start the KF Dedicated Server: %w", err)
@@ -170,7 +177,44 @@ func startGameServer(sett *settings.KFDSLSettings, ctx context.Context) (*kfserv
 	return gameServer, nil
 }
 
+// refreshSeasonalSpecimenType re-evaluates the 'auto' specimen type
+// and updates the server configuration file if the season has changed.
+func refreshSeasonalSpecimenType(sett *settings.KFDSLSettings, configFilePath string, snapshot *configSnapshot) {
+	previousType := sett.SpecimenType.Value()
+	if err := sett.SpecimenType.Parse(); err != nil {
+		log.Logger.Error("Unable to evaluate the seasonal specimen type", "error", err)
+		return
+	}
+
+	newType := sett.SpecimenType.Value()
+	if newType == previousType {
+		log.Logger.Debug("Seasonal specimen type unchanged",
+			"function", "refreshSeasonalSpecimenType", "specimenType", newType)
+		return
+	}
+
+	log.Logger.Info("Switching to the seasonal specimen type", "specimenType", sett.SpecimenType.FormattedValue())
+	if err := updateConfigFile(sett, configFilePath, snapshot); err != nil {
+		log.Logger.Error("Failed to update the KF Dedicated Server configuration file", "file", configFilePath, "error", err)
+		return
+	}
+	if err := snapshot.Save(); err != nil {
+		log.Logger.Error("Unable to save the configuration snapshot", "error", err)
+	}
+}
+
 func extractDefaultConfigFile(filename string, filePath string) error {
+	// Custom game modes may use a template from the disk
+	if filepath.IsAbs(filename) {
+		log.Logger.Debug("Copying default configuration file",
+			"function", "extractDefaultConfigFile", "sourceFile", filename, "destFile", filePath)
+
+		if err := utils.CopyAndReplaceFile(filename, filePath); err != nil {
+			return fmt.Errorf("failed to copy default config file %s: %w", filename, err)
+		}
+		return nil
+	}
+
 	defaultIniFilePath := filepath.Join("assets/configs", filename)
 
 	log.Logger.Debug("Extracting default configuration file",
@@ -182,13 +226,14 @@ func extractDefaultConfigFile(filename string, filePath string) error {
 	return nil
 }
 
-func updateConfigFile(sett *settings.KFDSLSettings) error {
-	kfiFileName := sett.ConfigFile.Value()
-	kfiFilePath := filepath.Join(viper.GetString("steamcmd-appinstalldir"), "System", kfiFileName)
-	tmEnabled := strings.Contains(strings.ToLower(sett.GameMode.Value()), "toygameinfo")
+func updateConfigFile(sett *settings.KFDSLSettings, kfiFilePath string, snapshot *configSnapshot) error {
+	kfiFileName := filepath.Base(kfiFilePath)
+
+	gameMode := kfserver.GetGameModeOrDefault(sett.GameMode.Value())
+	tmEnabled := gameMode.IniType == config.IniTypeToyGame
 
 	log.Logger.Debug("Starting server configuration file update",
-		"function", "updateConfigFile", "file", kfiFilePath)
+		"function", "updateConfigFile", "file", kfiFilePath, "gameMode", gameMode.Name, "iniType", gameMode.IniType)
 
 	if tmEnabled && !strings.EqualFold(strings.ToLower(kfiFileName), "toygame.ini") {
 		log.Logger.Warn("Toy Master game mode is enabled, but the configuration file is not 'ToyGame.ini'. This may cause unexpected behavior",
@@ -198,10 +243,7 @@ func updateConfigFile(sett *settings.KFDSLSettings) error {
 	// If the specified configuration file doesn't exists,
 	// let's extract the corresponding default file
 	if !utils.FileExists(kfiFilePath) {
-		defaultIniFileName := "KillingFloor.ini"
-		if tmEnabled {
-			defaultIniFileName = "ToyGame.ini"
-		}
+		defaultIniFileName := gameMode.IniTemplate
 
 		log.Logger.Debug("Missing server configuration file. Extracting the default one...",
 			"function", "updateConfigFile", "file", kfiFilePath, "defaultFileName", defaultIniFileName)
@@ -213,18 +255,13 @@ func updateConfigFile(sett *settings.KFDSLSettings) error {
 		}
 		log.Logger.Debug("Default server configuration file successfully extracted",
 			"function", "updateConfigFile", "file", kfiFilePath)
+
+		// The values written to the previous file are gone
+		snapshot.Forget(kfiFileName)
 	}
 
 	// Read the ini file
-	var kfi config.ServerIniFile
-	var err error
-
-	// Toy Master support
-	if tmEnabled {
-		kfi, err = config.NewKFTGIniFile(kfiFilePath)
-	} else {
-		kfi, err = config.NewKFIniFile(kfiFilePath)
-	}
+	kfi, err := config.NewServerIniFile(gameMode.IniType, kfiFilePath, gameMode.GameSection)
 	if err != nil {
 		log.Logger.Warn("Failed to read the server configuration file",
 			"function", "updateConfigFile", "file", kfiFilePath, "error", err)
@@ -234,41 +271,8 @@ func updateConfigFile(sett *settings.KFDSLSettings) error {
 	log.Logger.Debug("Server configuration file successfully loaded",
 		"function", "updateConfigFile", "file", kfiFilePath)
 
-	// Generics
-	cuList := []configUpdater[any]{
-		newConfigUpdater(sett.ServerName.Name(), func() any { return kfi.GetServerName() }, func(v any) bool { return kfi.SetServerName(v.(string)) }, sett.ServerName.Value()),
-		newConfigUpdater(sett.ShortName.Name(), func() any { return kfi.GetShortName() }, func(v any) bool { return kfi.SetShortName(v.(string)) }, sett.ShortName.Value()),
-		newConfigUpdater(sett.GamePort.Name(), func() any { return kfi.GetGamePort() }, func(v any) bool { return kfi.SetGamePort(v.(int)) }, sett.GamePort.Value()),
-		newConfigUpdater(sett.WebAdminPort.Name(), func() any { return kfi.GetWebAdminPort() }, func(v any) bool { return kfi.SetWebAdminPort(v.(int)) }, sett.WebAdminPort.Value()),
-		newConfigUpdater(sett.GameSpyPort.Name(), func() any { return kfi.GetGameSpyPort() }, func(v any) bool { return kfi.SetGameSpyPort(v.(int)) }, sett.GameSpyPort.Value()),
-		newConfigUpdater(sett.GameDifficulty.Name(), func() any { return kfi.GetGameDifficulty() }, func(v any) bool { return kfi.SetGameDifficulty(v.(int)) }, sett.GameDifficulty.Value()),
-		newConfigUpdater(sett.GameLength.Name(), func() any { return kfi.GetGameLength() }, func(v any) bool { return kfi.SetGameLength(v.(int)) }, sett.GameLength.Value()),
-		newConfigUpdater(sett.FriendlyFire.Name(), func() any { return kfi.GetFriendlyFireRate() }, func(v any) bool { return kfi.SetFriendlyFireRate(v.(float64)) }, sett.FriendlyFire.Value()),
-		newConfigUpdater(sett.MaxPlayers.Name(), func() any { return kfi.GetMaxPlayers() }, func(v any) bool { return kfi.SetMaxPlayers(v.(int)) }, sett.MaxPlayers.Value()),
-		newConfigUpdater(sett.MaxSpectators.Name(), func() any { return kfi.GetMaxSpectators() }, func(v any) bool { return kfi.SetMaxSpectators(v.(int)) }, sett.MaxSpectators.Value()),
-		newConfigUpdater(sett.Password.Name(), func() any { return kfi.GetPassword() }, func(v any) bool { return kfi.SetPassword(v.(string)) }, sett.Password.Value()),
-		newConfigUpdater(sett.Region.Name(), func() any { return kfi.GetRegion() }, func(v any) bool { return kfi.SetRegion(v.(int)) }, sett.Region.Value()),
-		newConfigUpdater(sett.AdminName.Name(), func() any { return kfi.GetAdminName() }, func(v any) bool { return kfi.SetAdminName(v.(string)) }, sett.AdminName.Value()),
-		newConfigUpdater(sett.AdminMail.Name(), func() any { return kfi.GetAdminMail() }, func(v any) bool { return kfi.SetAdminMail(v.(string)) }, sett.AdminMail.Value()),
-		newConfigUpdater(sett.AdminPassword.Name(), func() any { return kfi.GetAdminPassword() }, func(v any) bool { return kfi.SetAdminPassword(v.(string)) }, sett.AdminPassword.Value()),
-		newConfigUpdater(sett.MOTD.Value(), func() any { return kfi.GetMOTD() }, func(v any) bool { return kfi.SetMOTD(v.(string)) }, sett.MOTD.Value()),
-		newConfigUpdater(sett.SpecimenType.Name(), func() any { return kfi.GetSpecimenType() }, func(v any) bool { return kfi.SetSpecimenType(v.(string)) }, sett.SpecimenType.Value()),
-		newConfigUpdater(sett.RedirectURL.Name(), func() any { return kfi.GetRedirectURL() }, func(v any) bool { return kfi.SetRedirectURL(v.(string)) }, sett.RedirectURL.Value()),
-		newConfigUpdater(sett.EnableWebAdmin.Name(), func() any { return kfi.IsWebAdminEnabled() }, func(v any) bool { return kfi.SetWebAdminEnabled(v.(bool)) }, sett.EnableWebAdmin.Value()),
-		newConfigUpdater(sett.EnableMapVote.Name(), func() any { return kfi.IsMapVoteEnabled() }, func(v any) bool { return kfi.SetMapVoteEnabled(v.(bool)) == nil }, sett.EnableMapVote.Value()),
-		newConfigUpdater(sett.MapVoteRepeatLimit.Name(), func() any { return kfi.GetMapVoteRepeatLimit() }, func(v any) bool { return kfi.SetMapVoteRepeatLimit(v.(int)) }, sett.MapVoteRepeatLimit.Value()),
-	}
-	for _, conf := range cuList {
-		currentValue := conf.gv()
-		if currentValue != conf.nv {
-			if !conf.sv(conf.nv) {
-				log.Logger.Warn(fmt.Sprintf("Failed to update the server %s configuration", conf.name),
-					"function", "updateConfigFile", "file", kfiFilePath, "confName", conf.name, "confOldValue", currentValue, "confNewValue", conf.nv)
-				return fmt.Errorf("[%s]: failed to set the new value: %v", conf.name, conf.nv)
-			}
-			log.Logger.Debug(fmt.Sprintf("Updated server %s configuration", conf.name),
-				"function", "updateConfigFile", "file", kfiFilePath, "confName", conf.name, "confOldValue", currentValue, "confNewValue", conf.nv)
-		}
+	if err := applyIniMappings(kfi, sett.IniMappings("server"), "server", kfiFilePath, snapshot); err != nil {
+		return err
 	}
 
 	// Special cases
@@ -291,9 +295,15 @@ func updateConfigFile(sett *settings.KFDSLSettings) error {
 		return fmt.Errorf("[Maplist]: %w", err)
 	}
 
+	if err := applyConfigOverlays(kfi, kfiFileName, snapshot); err != nil {
+		return fmt.Errorf("[Overlays]: %w", err)
+	}
+
 	// Save the ini file
+	snapshot.SetMergeBase(kfi, kfiFileName)
 	err = kfi.Save(kfiFilePath)
 	if err == nil {
+		snapshot.RecordContents(kfi, kfiFileName)
 		log.Logger.Debug("Server configuration file successfully saved",
 			"function", "updateConfigFile", "file", kfiFilePath)
 	} else {
@@ -361,9 +371,9 @@ func updateConfigFileMaplist(iniFile config.ServerIniFile, sett *settings.KFDSLS
 		if mapList[0] == "all" {
 			// Fetch and set all available maps
 			gameServerRoot := viper.GetString("steamcmd-appinstalldir")
-			gameModePrefix := kfserver.GetGameModeMapPrefix(gameMode)
+			gameModePrefixes := kfserver.GetGameModeMapPrefixes(gameMode)
 
-			installedMaps, err := kfserver.GetInstalledMaps(path.Join(gameServerRoot, "Maps"), gameModePrefix)
+			installedMaps, err := kfserver.GetInstalledMaps(path.Join(gameServerRoot, "Maps"), gameModePrefixes...)
 			if err != nil {
 				log.Logger.Warn("Unable to fetch installed maps",
 					"function", "updateConfigFileMaplist", "file", iniFile.FilePath(), "gameMode", gameMode)
@@ -372,7 +382,7 @@ func updateConfigFileMaplist(iniFile config.ServerIniFile, sett *settings.KFDSLS
 
 			log.Logger.Debug("Using all maps for the current game mode",
 				"function", "updateConfigFileMaplist", "file", iniFile.FilePath(), "section", sectionName,
-				"gameMode", gameMode, "gameModePrefix", gameModePrefix, "serverRootDir", gameServerRoot, "installedMaps", installedMaps)
+				"gameMode", gameMode, "gameModePrefixes", gameModePrefixes, "serverRootDir", gameServerRoot, "installedMaps", installedMaps)
 
 			mapList = installedMaps
 		}
@@ -468,9 +478,7 @@ func setupKFPatcher(sett *settings.KFDSLSettings) error {
 	return nil
 }
 
-func updateKFPatcherConfigFile(sett *settings.KFDSLSettings) error {
-	kfpiFilePath := filepath.Join(viper.GetString("steamcmd-appinstalldir"), "System", "KFPatcherSettings.ini")
-
+func updateKFPatcherConfigFile(sett *settings.KFDSLSettings, kfpiFilePath string, snapshot *configSnapshot) error {
 	log.Logger.Debug("Starting KFPatcher configuration file update",
 		"function", "updateKFPatcherConfigFile", "file", kfpiFilePath)
 
@@ -485,29 +493,19 @@ func updateKFPatcherConfigFile(sett *settings.KFDSLSettings) error {
 	log.Logger.Debug("KFPatcher configuration file successfully loaded",
 		"function", "updateKFPatcherConfigFile", "file", kfpiFilePath)
 
-	cuList := []configUpdater[any]{
-		newConfigUpdater(sett.KFPHidePerks.Name(), func() any { return kfpi.IsShowPerksEnabled() }, func(v any) bool { return kfpi.SetShowPerksEnabled(v.(bool)) }, !sett.KFPHidePerks.Value()),
-		newConfigUpdater(sett.KFPDisableZedTime.Name(), func() any { return kfpi.IsZEDTimeEnabled() }, func(v any) bool { return kfpi.SetZEDTimeEnabled(v.(bool)) }, !sett.KFPDisableZedTime.Value()),
-		newConfigUpdater(sett.KFPEnableAllTraders.Name(), func() any { return kfpi.IsAllTradersOpenEnabled() }, func(v any) bool { return kfpi.SetAllTradersOpenEnabled(v.(bool)) }, sett.KFPEnableAllTraders.Value()),
-		newConfigUpdater(sett.KFPAllTradersMessage.Name(), func() any { return kfpi.GetAllTradersMessage() }, func(v any) bool { return kfpi.SetAllTradersMessage(v.(string)) }, sett.KFPAllTradersMessage.Value()),
-		newConfigUpdater(sett.KFPBuyEverywhere.Name(), func() any { return kfpi.IsBuyEverywhereEnabled() }, func(v any) bool { return kfpi.SetBuyEverywhereEnabled(v.(bool)) }, sett.KFPBuyEverywhere.Value()),
-	}
-	for _, conf := range cuList {
-		currentValue := conf.gv()
-		if currentValue != conf.nv {
-			if !conf.sv(conf.nv) {
-				log.Logger.Warn(fmt.Sprintf("Failed to update KFPatcher %s configuration", conf.name),
-					"function", "updateKFPatcherConfigFile", "file", kfpiFilePath, "confName", conf.name, "confOldValue", currentValue, "confNewValue", conf.nv)
-				return fmt.Errorf("[%s]: failed to set the new value: %v", conf.name, conf.nv)
-			}
-			log.Logger.Debug(fmt.Sprintf("Updated KFPatcher %s configuration", conf.name),
-				"function", "updateKFPatcherConfigFile", "file", kfpiFilePath, "confName", conf.name, "confOldValue", currentValue, "confNewValue", conf.nv)
-		}
+	if err := applyIniMappings(kfpi, sett.IniMappings("kfpatcher"), "KFPatcher", kfpiFilePath, snapshot); err != nil {
+		return err
+	}
+
+	if err := applyConfigOverlays(kfpi, filepath.Base(kfpiFilePath), snapshot); err != nil {
+		return fmt.Errorf("[Overlays]: %w", err)
 	}
 
 	// Save the ini file
+	snapshot.SetMergeBase(kfpi, filepath.Base(kfpiFilePath))
 	err = kfpi.Save(kfpiFilePath)
 	if err == nil {
+		snapshot.RecordContents(kfpi, filepath.Base(kfpiFilePath))
 		log.Logger.Debug("KFPatcher configuration file successfully saved",
 			"function", "updateKFPatcherConfigFile", "file", kfpiFilePath)
 	} else {
@@ -517,6 +515,124 @@ func updateKFPatcherConfigFile(sett *settings.KFDSLSettings) error {
 	return err
 }
 
+// overlayIniFile is an ini file accepting overlay fragments.
+type overlayIniFile interface {
+	ApplyOverlayDir(dir string) ([]string, error)
+}
+
+// configOverlayDir returns the overlay directory of a configuration file.
+// It's always located in the server installation directory, so the rendered scratch copies get the same overlays.
+func configOverlayDir(fileName string) string {
+	return filepath.Join(viper.GetString("steamcmd-appinstalldir"), "System", "kfdsl.d", fileName+".d")
+}
+
+// applyConfigOverlays merges the overlay fragments of a configuration file, in lexical order.
+// The values they change are recorded in the snapshot so they aren't reported as drifted.
+func applyConfigOverlays(iniFile overlayIniFile, fileName string, snapshot *configSnapshot) error {
+	overlayDir := configOverlayDir(fileName)
+	written := snapshot.File(fileName)
+	previousValues := iniPropertyValues(iniFile, written)
+
+	applied, err := iniFile.ApplyOverlayDir(overlayDir)
+	for _, fragmentPath := range applied {
+		log.Logger.Info("Configuration overlay applied", "file", fileName, "overlay", fragmentPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	for property, value := range iniPropertyValues(iniFile, written) {
+		if value != previousValues[property] {
+			written[property] = value
+		}
+	}
+	return nil
+}
+
+// applyIniMappings writes the mapped settings to an ini file through its property accessors,
+// leaving the properties already set to the right value untouched.
+// The values changed since the last snapshot are handled according to the snapshot drift policy.
+func applyIniMappings(iniFile any, mappings []settings.IniMapping, fileLabel string, filePath string, snapshot *configSnapshot) error {
+	fileName := filepath.Base(filePath)
+	written := snapshot.File(fileName)
+	kept := snapshot.KeptValues(fileName)
+
+	var drifted []string
+	for _, mapping := range mappings {
+		getter := config.PropertyGetter(iniFile, mapping.Property)
+		setter := config.PropertySetter(iniFile, mapping.Property)
+		if !getter.IsValid() || !setter.IsValid() {
+			return fmt.Errorf("[%s]: no accessors found for the %s property '%s'", mapping.Name, fileLabel, mapping.Property)
+		}
+
+		newValue := reflect.ValueOf(mapping.Value)
+		if setter.Type().NumIn() != 1 || !newValue.Type().AssignableTo(setter.Type().In(0)) {
+			return fmt.Errorf("[%s]: the %s property '%s' doesn't accept a %T value", mapping.Name, fileLabel, mapping.Property, mapping.Value)
+		}
+
+		currentValue := getter.Call(nil)[0].Interface()
+		writtenValue, ok := written[mapping.Property]
+		if ok && isSensitiveSnapshotValue(writtenValue) != mapping.IsSensitive {
+			// The property sensitivity has changed, the snapshot value can't be compared
+			ok = false
+		}
+		launcherValue := snapshotValue(mapping.Value, mapping.IsSensitive)
+		if drift, changed := compareSnapshotValue(fileName, mapping.Property, writtenValue, currentValue); ok && changed {
+			logConfigDrift(drift, snapshot.policy)
+
+			switch snapshot.policy {
+			case DriftPolicyKeep:
+				// The external value is the new reference, the launcher one is declined until it changes
+				written[mapping.Property] = snapshotValue(currentValue, mapping.IsSensitive)
+				kept[mapping.Property] = launcherValue
+				continue
+			case DriftPolicyFail:
+				drifted = append(drifted, mapping.Property)
+				continue
+			}
+		}
+		if keptValue, ok := kept[mapping.Property]; ok {
+			if snapshot.policy == DriftPolicyKeep && keptValue == launcherValue {
+				continue
+			}
+			delete(kept, mapping.Property)
+		}
+		if written != nil {
+			written[mapping.Property] = launcherValue
+		}
+
+		if currentValue == mapping.Value {
+			continue
+		}
+
+		logOldValue, logNewValue := currentValue, mapping.Value
+		if mapping.IsSensitive {
+			logOldValue, logNewValue = redactedValue, redactedValue
+		}
+
+		// Setters either report a success or return an error
+		failed := false
+		switch result := setter.Call([]reflect.Value{newValue})[0].Interface().(type) {
+		case bool:
+			failed = !result
+		case error:
+			failed = true
+		}
+		if failed {
+			log.Logger.Warn(fmt.Sprintf("Failed to update the %s %s configuration", fileLabel, mapping.Name),
+				"function", "applyIniMappings", "file", filePath, "confName", mapping.Name, "confOldValue", logOldValue, "confNewValue", logNewValue)
+			return fmt.Errorf("[%s]: failed to set the new value: %v", mapping.Name, logNewValue)
+		}
+		log.Logger.Debug(fmt.Sprintf("Updated %s %s configuration", fileLabel, mapping.Name),
+			"function", "applyIniMappings", "file", filePath, "confName", mapping.Name, "confOldValue", logOldValue, "confNewValue", logNewValue)
+	}
+
+	if len(drifted) > 0 {
+		return fmt.Errorf("%s properties changed outside of the launcher: %s", fileLabel, strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
 func updateGameServerSteamLibs() ([]string, error) {
 	ret := []string{}
 	rootDir := viper.GetString("steamcmd-appinstalldir")
@@ -526,18 +642,22 @@ func updateGameServerSteamLibs() ([]string, error) {
 	log.Logger.Debug("Starting server Steam libraries update",
 		"function", "updateGameServerSteamLibs", "rootDir", rootDir, "systemDir", systemDir, "libsDir", libsDir)
 
-	libs := map[string]string{
-		path.Join(libsDir, "steamclient.so"):  path.Join(systemDir, "steamclient.so"),
-		path.Join(libsDir, "libtier0_s.so"):   path.Join(systemDir, "libtier0_s.so"),
-		path.Join(libsDir, "libvstdlib_s.so"): path.Join(systemDir, "libvstdlib_s.so"),
+	libs := map[string]string{}
+	for _, lib := range steamLibraries {
+		libs[path.Join(libsDir, lib)] = path.Join(systemDir, lib)
 	}
 
 	for srcFile, dstFile := range libs {
-		identical, err := utils.SHA1Compare(srcFile, dstFile)
-		if err != nil {
-			log.Logger.Warn("Error comparing file checksums",
-				"function", "updateGameServerSteamLibs", "sourceFile", srcFile, "destFile", dstFile, "error", err)
-			return ret, fmt.Errorf("error comparing files %s and %s: %w", srcFile, dstFile, err)
+		// Missing libraries are copied as is
+		identical := false
+		if utils.FileExists(dstFile) {
+			var err error
+			identical, err = utils.SHA1Compare(srcFile, dstFile)
+			if err != nil {
+				log.Logger.Warn("Error comparing file checksums",
+					"function", "updateGameServerSteamLibs", "sourceFile", srcFile, "destFile", dstFile, "error", err)
+				return ret, fmt.Errorf("error comparing files %s and %s: %w", srcFile, dstFile, err)
+			}
 		}
 
 		if !identical {
@@ -560,6 +680,30 @@ func updateGameServerSteamLibs() ([]string, error) {
 	return ret, nil
 }
 
+// newSecretResolver returns a secret resolver using the providers configured by the secrets-* flags.
+func newSecretResolver() (*secrets.Resolver, error) {
+	storeProvider := &secrets.StoreProvider{}
+	if storePath := viper.GetString("secrets-store"); storePath != "" {
+		store, err := secrets.OpenStore(storePath, viper.GetString("secrets-store-key-file"))
+		if err != nil {
+			return nil, err
+		}
+		storeProvider.Store = store
+	}
+
+	resolver, err := secrets.NewResolver(
+		viper.GetString("secrets-order"),
+		&secrets.DirProvider{Dir: viper.GetString("secrets-dir")},
+		&secrets.FileProvider{Path: viper.GetString("secrets-file")},
+		storeProvider,
+		&secrets.EnvProvider{},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets order: %w", err)
+	}
+	return resolver, nil
+}
+
 func readSteamCredentials(sett *settings.KFDSLSettings) error {
 	var fromEnv bool
 
@@ -567,32 +711,40 @@ func readSteamCredentials(sett *settings.KFDSLSettings) error {
 		if fromEnv {
 			_ = os.Unsetenv("STEAMACC_USERNAME")
 			_ = os.Unsetenv("STEAMACC_PASSWORD")
+			_ = os.Unsetenv("STEAMACC_USERNAME_FILE")
+			_ = os.Unsetenv("STEAMACC_PASSWORD_FILE")
 		}
 	}()
 
 	log.Logger.Debug("Starting Steam credential retrieval",
 		"function", "readSteamCredentials")
 
-	// Try reading from Docker Secrets
-	log.Logger.Debug("Attempting to read from Docker Secrets",
-		"function", "readSteamCredentials")
-	steamUsername, errUser := secrets.Read("steamacc_username")
-	steamPassword, errPass := secrets.Read("steamacc_password")
-
-	// Fallback to environment variables if secrets are missing
-	if errUser != nil {
-		log.Logger.Debug("Secret not found, falling back to environment variable",
-			"function", "readSteamCredentials", "secret", "steamacc_username", "error", errUser)
-		steamUsername = viper.GetString("STEAMACC_USERNAME")
-		fromEnv = true
-	}
-	if errPass != nil {
-		log.Logger.Debug("Secret not found, falling back to environment variable",
-			"function", "readSteamCredentials", "secret", "steamacc_password", "error", errPass)
-		steamPassword = viper.GetString("STEAMACC_PASSWORD")
-		fromEnv = true
+	resolver, err := newSecretResolver()
+	if err != nil {
+		return err
+	}
+
+	steamUsername, usernameProvider, err := resolver.Read("steamacc_username")
+	if errors.Is(err, secrets.ErrNotFound) {
+		log.Logger.Debug("Secret not found, using the default Steam login",
+			"function", "readSteamCredentials", "secret", "steamacc_username", "error", err)
+		steamUsername = settings.DefaultSteamLogin
+	} else if err != nil {
+		return err
+	}
+
+	steamPassword, passwordProvider, err := resolver.Read("steamacc_password")
+	if errors.Is(err, secrets.ErrNotFound) {
+		log.Logger.Debug("Secret not found",
+			"function", "readSteamCredentials", "secret", "steamacc_password", "error", err)
+	} else if err != nil {
+		return err
 	}
 
+	log.Logger.Debug("Steam credentials lookup complete",
+		"function", "readSteamCredentials", "usernameProvider", usernameProvider, "passwordProvider", passwordProvider)
+	fromEnv = usernameProvider == "env" || passwordProvider == "env"
+
 	// Ensure both credentials are present
 	if steamUsername == "" || steamPassword == "" {
 		log.Logger.Debug("Missing Steam credentials, aborting",
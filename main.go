@@ -16,26 +16,31 @@ import (
 func main() {
 	// Build the root command and execute it
 	rootCmd := cmd.BuildRootCommand()
-	if err := rootCmd.Execute(); err != nil {
+	rootCmd.AddCommand(
+		cmd.BuildRenderCommand(renderConfigFiles),
+		cmd.BuildDriftCommand(detectConfigDrift),
+		cmd.BuildMapsCommand(),
+		cmd.BuildDoctorCommand(runDoctor),
+		cmd.BuildInstallCommand(installGameServer),
+		cmd.BuildUpdateCommand(installGameServer),
+		cmd.BuildIniCommand(),
+		cmd.BuildHealthcheckCommand(),
+		cmd.BuildSettingsCommand(),
+		cmd.BuildSecretsCommand(),
+	)
+	execCmd, err := rootCmd.ExecuteC()
+	if err != nil {
 		os.Exit(1)
 	}
 
+	// Subcommands and help requests exit without starting the server
+	if help, _ := execCmd.Flags().GetBool("help"); help || execCmd != rootCmd {
+		return
+	}
+
 	// Get the settings
 	sett := settings.Get()
 
-	// Init the logger
-	log.Init(
-		sett.LogLevel.Value(),
-		sett.LogFile.Value(),
-		sett.LogFileFormat.Value(),
-		sett.LogMaxSize.Value(),
-		sett.LogMaxBackups.Value(),
-		sett.LogMaxAge.Value(),
-		sett.LogToFile.Value(),
-	)
-	log.Logger.Debug("Log system initialized",
-		"function", "main")
-
 	// Create a cancel context
 	ctx, cancel := context.WithCancel(context.Background())
 	signalChan := make(chan os.Signal, 1)
@@ -47,7 +52,7 @@ func main() {
 	// Start SteamCMD, if enabled
 	if !sett.NoSteam.Value() {
 		start := time.Now()
-		if err := startSteamCMD(sett, ctx); err != nil {
+		if err := startSteamCMD(sett, !sett.NoValidate.Value(), ctx); err != nil {
 			log.Logger.Error("SteamCMD raised an error", "error", err)
 			os.Exit(1)
 		}
@@ -79,13 +84,31 @@ func main() {
 
 	// Start the Killing Floor Dedicated Server
 	startTime = time.Now()
-	server, err := startGameServer(sett, ctx)
+	server, err = startGameServer(sett, ctx)
 	if err != nil {
 		log.Logger.Error("KF Dedicated Server raised an error", "error", err)
 		return
 	}
 
-	<-signalChan
+	// Reload the settings on SIGHUP, until the launcher is stopped
+	reloadChan := make(chan os.Signal, 1)
+	signal.Notify(reloadChan, syscall.SIGHUP)
+
+	for running := true; running; {
+		select {
+		case <-reloadChan:
+			server, err = reloadGameServer(sett, server, func() (*settings.KFDSLSettings, func() error, error) { return cmd.ReloadSettings(rootCmd) }, ctx)
+			if err != nil {
+				log.Logger.Error("Unable to reload the KF Dedicated Server", "error", err)
+				if server == nil {
+					return
+				}
+			}
+		case <-signalChan:
+			running = false
+		}
+	}
+	signal.Stop(reloadChan)
 	signal.Stop(signalChan)
 	log.Logger.Debug("Program finished, exiting now...",
 		"function", "main")
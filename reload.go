@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/services/kfserver"
+	"github.com/K4rian/kfdsl/internal/settings"
+)
+
+// Interval between two player count checks while waiting for the server to be empty
+const emptyServerPollInterval = 15 * time.Second
+
+// Guards the settings and the viper configuration shared with the server restart hook
+var settingsMu sync.Mutex
+
+// reloadGameServer reloads the settings, then restarts the server so the new values take effect.
+// The running server and the current settings are kept until the restart, or if the settings can't be reloaded.
+func reloadGameServer(sett *settings.KFDSLSettings, server *kfserver.KFServer, reloadSettings func() (*settings.KFDSLSettings, func() error, error), ctx context.Context) (*kfserver.KFServer, error) {
+	log.Logger.Info("Reloading the settings...")
+
+	// The running server still uses the previous query port
+	queryAddress := net.JoinHostPort("127.0.0.1", strconv.Itoa(sett.GamePort.Value()+1))
+
+	// Reading the settings swaps the viper configuration for a while, the restart hook must not see it
+	settingsMu.Lock()
+	newSett, commitSettings, err := reloadSettings()
+	settingsMu.Unlock()
+	if err != nil {
+		return server, fmt.Errorf("invalid settings, keeping the current ones: %w", err)
+	}
+	newSett.Print()
+
+	if viper.GetBool("reload-wait-empty") && server.IsRunning() {
+		timeout := time.Duration(viper.GetInt("reload-wait-timeout")) * time.Minute
+		if err := waitForEmptyServer(ctx, queryAddress, timeout); err != nil {
+			return server, fmt.Errorf("restart cancelled: %w", err)
+		}
+	}
+
+	settingsMu.Lock()
+	err = commitSettings()
+	settingsMu.Unlock()
+	if err != nil {
+		return server, fmt.Errorf("unable to apply the new settings: %w", err)
+	}
+
+	log.Logger.Info("Restarting the KF Dedicated Server...")
+	if err := server.Stop(); err != nil {
+		return server, fmt.Errorf("unable to stop the KF Dedicated Server: %w", err)
+	}
+	if err := server.Wait(); err != nil {
+		log.Logger.Debug("KF Dedicated Server stopped with an error",
+			"function", "reloadGameServer", "error", err)
+	}
+
+	newServer, err := startGameServer(sett, ctx)
+	if err != nil {
+		return nil, err
+	}
+	log.Logger.Info("KF Dedicated Server restarted with the new settings")
+	return newServer, nil
+}
+
+// waitForEmptyServer blocks until no player is connected to the server, or the timeout expires (0 means no timeout).
+// It returns an error if the launcher is stopped meanwhile.
+func waitForEmptyServer(ctx context.Context, queryAddress string, timeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+
+	for {
+		queryCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		players, err := kfserver.QueryPlayers(queryCtx, queryAddress)
+		cancel()
+
+		if err != nil {
+			// Don't hold the restart back if the player count can't be read
+			log.Logger.Warn("Unable to query the number of players, restarting anyway", "error", err)
+			return nil
+		}
+		if players == 0 {
+			return nil
+		}
+
+		log.Logger.Info("Waiting for the server to be empty before restarting", "players", players)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			log.Logger.Info("Timed out waiting for the server to be empty, restarting now", "players", players)
+			return nil
+		case <-time.After(emptyServerPollInterval):
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+
+	"github.com/K4rian/kfdsl/internal/config/ini"
+	"github.com/K4rian/kfdsl/internal/log"
+	"github.com/K4rian/kfdsl/internal/settings"
+	"github.com/K4rian/kfdsl/internal/utils"
+)
+
+// renderConfigFiles applies the settings to a scratch copy of the configuration files
+// and writes a unified diff of the changes to out. The server files are left untouched.
+func renderConfigFiles(sett *settings.KFDSLSettings, out io.Writer) error {
+	rootDir := viper.GetString("steamcmd-appinstalldir")
+
+	scratchDir, err := os.MkdirTemp("", "kfdsl-render-")
+	if err != nil {
+		return fmt.Errorf("failed to create the scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratchDir)
+
+	log.Logger.Debug("Rendering configuration files",
+		"function", "renderConfigFiles", "rootDir", rootDir, "scratchDir", scratchDir)
+
+	// The snapshot is only read, to render the configuration the drift policy would produce
+	snapshot, err := loadConfigSnapshot(configSnapshotPath(rootDir), viper.GetString("drift-policy"))
+	if err != nil {
+		return err
+	}
+
+	configFileName := sett.ConfigFile.Value()
+	if err := renderConfigFile(rootDir, scratchDir, configFileName, out, func(filePath string) error {
+		return updateConfigFile(sett, filePath, snapshot)
+	}); err != nil {
+		return fmt.Errorf("failed to render the server configuration file %s: %w", configFileName, err)
+	}
+
+	if sett.EnableKFPatcher.Value() {
+		kfpConfigFileName := "KFPatcherSettings.ini"
+		if !utils.FileExists(filepath.Join(rootDir, "System", kfpConfigFileName)) {
+			log.Logger.Warn("KFPatcher is not installed yet, skipping its configuration file",
+				"function", "renderConfigFiles", "file", kfpConfigFileName)
+			return nil
+		}
+
+		if err := renderConfigFile(rootDir, scratchDir, kfpConfigFileName, out, func(filePath string) error {
+			return updateKFPatcherConfigFile(sett, filePath, snapshot)
+		}); err != nil {
+			return fmt.Errorf("failed to render the KFPatcher configuration file %s: %w", kfpConfigFileName, err)
+		}
+	}
+	return nil
+}
+
+// renderConfigFile copies a System configuration file into the scratch directory,
+// runs the update function against the copy and writes the resulting diff.
+func renderConfigFile(rootDir string, scratchDir string, fileName string, out io.Writer, update func(filePath string) error) error {
+	srcFilePath := filepath.Join(rootDir, "System", fileName)
+	dstFilePath := filepath.Join(scratchDir, "System", fileName)
+
+	if err := os.MkdirAll(filepath.Dir(dstFilePath), 0755); err != nil {
+		return err
+	}
+
+	// The contents are diffed once decoded, as the ini files may be UTF-16
+	var original string
+	fromName := "/dev/null"
+	if utils.FileExists(srcFilePath) {
+		data, err := os.ReadFile(srcFilePath)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(dstFilePath, data, 0644); err != nil {
+			return err
+		}
+		if original, err = ini.ReadFileContent(srcFilePath); err != nil {
+			return err
+		}
+		fromName = filepath.Join("a", "System", fileName)
+	}
+
+	if err := update(dstFilePath); err != nil {
+		return err
+	}
+
+	updated, err := ini.ReadFileContent(dstFilePath)
+	if err != nil {
+		return err
+	}
+
+	diff := utils.UnifiedDiff(fromName, filepath.Join("b", "System", fileName), original, updated, 3)
+	if diff == "" {
+		log.Logger.Debug("No changes to the configuration file",
+			"function", "renderConfigFile", "file", srcFilePath)
+		return nil
+	}
+
+	_, err = io.WriteString(out, diff)
+	return err
+}